@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/poy/petasos/maintainer"
+)
+
+// config is read from the -config file and then overridden by any flags
+// that were set. Zero values keep the maintainer's defaults.
+type config struct {
+	Dir         string     `json:"dir"`
+	Storage     stringList `json:"storage"`
+	WriteQuorum int        `json:"write_quorum"`
+	Codec       string     `json:"codec"`
+	Topic       string     `json:"topic"`
+
+	Routers       stringList `json:"routers"`
+	Transport     string     `json:"transport"`
+	MetricsPath   string     `json:"metrics_path"`
+	RouterTimeout duration   `json:"router_timeout"`
+	Quorum        int        `json:"quorum"`
+
+	DeltaCacheSize int      `json:"delta_cache_size"`
+	DeltaWindow    duration `json:"delta_window"`
+
+	StatusAddr string `json:"status_addr"`
+
+	Balancer balancerConfig `json:"balancer"`
+	Filler   fillerConfig   `json:"filler"`
+}
+
+type balancerConfig struct {
+	Interval             duration `json:"interval"`
+	MaxWritesPerInterval uint64   `json:"max_writes_per_interval"`
+	MinWritesPerInterval uint64   `json:"min_writes_per_interval"`
+	MaxBytesPerInterval  uint64   `json:"max_bytes_per_interval"`
+	MinBytesPerInterval  uint64   `json:"min_bytes_per_interval"`
+	MinCount             uint64   `json:"min_count"`
+	MaxCount             uint64   `json:"max_count"`
+	MaxActions           uint64   `json:"max_actions_per_interval"`
+	CooldownIntervals    uint64   `json:"cooldown_intervals"`
+	HysteresisIntervals  uint64   `json:"hysteresis_intervals"`
+	Smoothing            float64  `json:"smoothing"`
+	BalanceOn            string   `json:"balance_on"`
+	MaxLatency           duration `json:"max_latency"`
+}
+
+type fillerConfig struct {
+	Interval duration `json:"interval"`
+	MinCount uint64   `json:"min_count"`
+}
+
+func defaultConfig() config {
+	return config{
+		Dir:            ".",
+		Codec:          "json",
+		Transport:      "http",
+		MetricsPath:    "/metrics",
+		RouterTimeout:  duration{5 * time.Second},
+		DeltaCacheSize: 10000,
+		StatusAddr:     ":8081",
+		Balancer: balancerConfig{
+			Interval:  duration{5 * time.Second},
+			BalanceOn: "writes",
+		},
+		Filler: fillerConfig{
+			Interval: duration{5 * time.Second},
+		},
+	}
+}
+
+// loadConfig parses the flags, then the -config file (if given) and then
+// re-applies the flags that were set so they take precedence.
+func loadConfig(fset *flag.FlagSet, args []string) (config, error) {
+	conf := defaultConfig()
+	path := fset.String("config", "", "JSON config file (flags take precedence)")
+	bindFlags(fset, &conf)
+
+	if err := fset.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	set := make(map[string]string)
+	fset.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	if *path == "" {
+		return conf, nil
+	}
+
+	data, err := os.ReadFile(*path)
+	if err != nil {
+		return config{}, err
+	}
+
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return config{}, fmt.Errorf("invalid config %s: %s", *path, err)
+	}
+
+	for name, value := range set {
+		fset.Set(name, value)
+	}
+
+	return conf, nil
+}
+
+func bindFlags(fset *flag.FlagSet, c *config) {
+	fset.StringVar(&c.Dir, "dir", c.Dir, "directory holding the range files")
+	fset.Var(&c.Storage, "storage", "comma separated petasos-storage nodes to use instead of -dir (several are replicated to)")
+	fset.IntVar(&c.WriteQuorum, "write-quorum", c.WriteQuorum, "storage nodes that must accept each write (0 for a majority)")
+	fset.StringVar(&c.Codec, "codec", c.Codec, "range name codec (json, hex or path)")
+	fset.StringVar(&c.Topic, "topic", c.Topic, "topic of the range set (optional)")
+
+	fset.Var(&c.Routers, "routers", "comma separated router addresses")
+	fset.StringVar(&c.Transport, "transport", c.Transport, "how to reach the routers' metrics (http or grpc)")
+	fset.StringVar(&c.MetricsPath, "metrics-path", c.MetricsPath, "path the routers serve HTTP metrics on")
+	fset.Var(&c.RouterTimeout, "router-timeout", "timeout for each router")
+	fset.IntVar(&c.Quorum, "quorum", c.Quorum, "routers that must respond (0 for all)")
+
+	fset.IntVar(&c.DeltaCacheSize, "delta-cache-size", c.DeltaCacheSize, "range files to remember metrics for")
+	fset.Var(&c.DeltaWindow, "delta-window", "window the write rates are measured over (defaults to the balancer interval)")
+
+	fset.StringVar(&c.StatusAddr, "status-addr", c.StatusAddr, "address to serve /healthz, /status and /metrics on")
+
+	b := &c.Balancer
+	fset.Var(&b.Interval, "balancer-interval", "how often to balance")
+	fset.Uint64Var(&b.MaxWritesPerInterval, "max-writes-per-interval", 0, "writes per interval above which a range is split")
+	fset.Uint64Var(&b.MinWritesPerInterval, "min-writes-per-interval", 0, "writes per interval below which ranges are combined")
+	fset.Uint64Var(&b.MaxBytesPerInterval, "max-bytes-per-interval", 0, "bytes per interval above which a range is split")
+	fset.Uint64Var(&b.MinBytesPerInterval, "min-bytes-per-interval", 0, "bytes per interval below which ranges are combined")
+	fset.Uint64Var(&b.MinCount, "min-count", 0, "fewest ranges")
+	fset.Uint64Var(&b.MaxCount, "max-count", 0, "most ranges")
+	fset.Uint64Var(&b.MaxActions, "max-actions-per-interval", 0, "splits and combines per interval")
+	fset.Uint64Var(&b.CooldownIntervals, "cooldown-intervals", 0, "intervals a new range is left alone (defaults to 3)")
+	fset.Uint64Var(&b.HysteresisIntervals, "hysteresis-intervals", 0, "intervals a range must stay hot or cold (defaults to 2)")
+	fset.Float64Var(&b.Smoothing, "smoothing", 0, "EWMA smoothing factor in (0, 1]")
+	fset.StringVar(&b.BalanceOn, "balance-on", b.BalanceOn, "writes, bytes or writes-and-bytes")
+	fset.Var(&b.MaxLatency, "max-latency", "p99 write latency above which a range is degraded")
+
+	f := &c.Filler
+	fset.Var(&f.Interval, "filler-interval", "how often to look for gaps")
+	fset.Uint64Var(&f.MinCount, "filler-min-count", 0, "ranges required before filling gaps")
+}
+
+var balanceOn = map[string]maintainer.BalanceOn{
+	"writes":           maintainer.BalanceOnWrites,
+	"bytes":            maintainer.BalanceOnBytes,
+	"writes-and-bytes": maintainer.BalanceOnWritesAndBytes,
+}
+
+func (c balancerConfig) opts() ([]maintainer.BalancerOpts, error) {
+	on, ok := balanceOn[c.BalanceOn]
+	if !ok {
+		return nil, fmt.Errorf("unknown balance-on: %q", c.BalanceOn)
+	}
+
+	opts := []maintainer.BalancerOpts{
+		maintainer.WithBalanceOn(on),
+	}
+
+	add := func(set bool, opt maintainer.BalancerOpts) {
+		if set {
+			opts = append(opts, opt)
+		}
+	}
+
+	add(c.Interval.Duration > 0, maintainer.WithBalancerInterval(c.Interval.Duration))
+	add(c.MaxWritesPerInterval > 0, maintainer.WithMaxWritesPerInterval(c.MaxWritesPerInterval))
+	add(c.MinWritesPerInterval > 0, maintainer.WithMinWritesPerInterval(c.MinWritesPerInterval))
+	add(c.MaxBytesPerInterval > 0, maintainer.WithMaxBytesPerInterval(c.MaxBytesPerInterval))
+	add(c.MinBytesPerInterval > 0, maintainer.WithMinBytesPerInterval(c.MinBytesPerInterval))
+	add(c.MinCount > 0, maintainer.WithMinCount(c.MinCount))
+	add(c.MaxCount > 0, maintainer.WithMaxCount(c.MaxCount))
+	add(c.MaxActions > 0, maintainer.WithMaxActionsPerInterval(c.MaxActions))
+	add(c.CooldownIntervals > 0, maintainer.WithCooldownIntervals(c.CooldownIntervals))
+	add(c.HysteresisIntervals > 0, maintainer.WithHysteresisIntervals(c.HysteresisIntervals))
+	add(c.Smoothing > 0, maintainer.WithSmoothing(c.Smoothing))
+	add(c.MaxLatency.Duration > 0, maintainer.WithMaxLatency(c.MaxLatency.Duration))
+
+	return opts, nil
+}
+
+func (c fillerConfig) opts() []maintainer.FillerOpts {
+	var opts []maintainer.FillerOpts
+	if c.Interval.Duration > 0 {
+		opts = append(opts, maintainer.WithFillerInterval(c.Interval.Duration))
+	}
+
+	if c.MinCount > 0 {
+		opts = append(opts, maintainer.WithFillerMinCount(c.MinCount))
+	}
+
+	return opts
+}
+
+// duration is a time.Duration written as a string (e.g., "5s") in the
+// config file and flags.
+type duration struct {
+	time.Duration
+}
+
+func (d *duration) Set(s string) (err error) {
+	d.Duration, err = time.ParseDuration(s)
+	return err
+}
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	return d.Set(s)
+}
+
+// stringList is a comma separated list in flags and a JSON array in the
+// config file.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = strings.Split(s, ",")
+	return nil
+}
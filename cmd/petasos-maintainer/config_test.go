@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	writeConfig := func(t *testing.T, data string) string {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	load := func(args ...string) (config, error) {
+		return loadConfig(flag.NewFlagSet("test", flag.ContinueOnError), args)
+	}
+
+	o.Spec("it uses the defaults", func(t *testing.T) {
+		conf, err := load()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, conf).To(Equal(defaultConfig()))
+	})
+
+	o.Spec("it uses the flags", func(t *testing.T) {
+		conf, err := load("-codec", "hex", "-routers", "a,b", "-balancer-interval", "1s")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, conf.Codec).To(Equal("hex"))
+		Expect(t, conf.Routers).To(Equal(stringList{"a", "b"}))
+		Expect(t, conf.Balancer.Interval.Duration).To(Equal(time.Second))
+	})
+
+	o.Spec("it overrides the defaults with the config file", func(t *testing.T) {
+		path := writeConfig(t, `{
+			"codec": "path",
+			"routers": ["a", "b"],
+			"balancer": {"interval": "2s", "max_writes_per_interval": 100}
+		}`)
+
+		conf, err := load("-config", path)
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, conf.Codec).To(Equal("path"))
+		Expect(t, conf.Routers).To(Equal(stringList{"a", "b"}))
+		Expect(t, conf.Balancer.Interval.Duration).To(Equal(2 * time.Second))
+		Expect(t, conf.Balancer.MaxWritesPerInterval).To(Equal(uint64(100)))
+		Expect(t, conf.Transport).To(Equal("http"))
+	})
+
+	o.Spec("it overrides the config file with the flags that were set", func(t *testing.T) {
+		path := writeConfig(t, `{
+			"codec": "path",
+			"routers": ["a", "b"],
+			"balancer": {"interval": "2s", "max_writes_per_interval": 100}
+		}`)
+
+		conf, err := load("-routers", "c", "-config", path, "-max-writes-per-interval", "200")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, conf.Codec).To(Equal("path"))
+		Expect(t, conf.Routers).To(Equal(stringList{"c"}))
+		Expect(t, conf.Balancer.Interval.Duration).To(Equal(2 * time.Second))
+		Expect(t, conf.Balancer.MaxWritesPerInterval).To(Equal(uint64(200)))
+	})
+
+	o.Spec("it returns an error for an invalid config file", func(t *testing.T) {
+		_, err := load("-config", writeConfig(t, `{"codec": 1}`))
+		Expect(t, err == nil).To(BeFalse())
+
+		_, err = load("-config", filepath.Join(t.TempDir(), "missing.json"))
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
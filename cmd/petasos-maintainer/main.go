@@ -0,0 +1,177 @@
+// petasos-maintainer runs the balancer and filler against the range files in
+// a directory. It reads the routers' metrics to decide when to split and
+// combine ranges and serves its health, status and Prometheus metrics over
+// HTTP.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/poy/petasos/internal/storage"
+	"github.com/poy/petasos/maintainer"
+	"github.com/poy/petasos/metrics"
+	metricsgrpc "github.com/poy/petasos/metrics/grpc"
+	metricshttp "github.com/poy/petasos/metrics/http"
+	"github.com/poy/petasos/promcollector"
+	"github.com/poy/petasos/router"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// actionLogSize is how many actions /status reports.
+const actionLogSize = 100
+
+func main() {
+	conf, err := loadConfig(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	logger := slog.Default()
+
+	if err := run(logger, conf); err != nil {
+		logger.Error("maintainer failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger *slog.Logger, conf config) error {
+	codec, err := router.ParseCodec(conf.Codec)
+	if err != nil {
+		return err
+	}
+
+	if len(conf.Routers) == 0 {
+		return errors.New("at least one router is required")
+	}
+
+	balancerOpts, err := conf.Balancer.opts()
+	if err != nil {
+		return err
+	}
+
+	fs, closeFS, err := storage.Open(conf.Dir, conf.Storage, conf.WriteQuorum, logger)
+	if err != nil {
+		return err
+	}
+	defer closeFS()
+
+	balancerMetrics, fillerMetrics, closeMetrics, err := newRangeMetrics(conf)
+	if err != nil {
+		return err
+	}
+	defer closeMetrics()
+
+	actions := newActionLog(actionLogSize)
+
+	// The maintainer does not route writes, so the collector only counts
+	// the balancer's and filler's actions.
+	collector := promcollector.New(nil)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	observer := maintainer.ObserverFunc(func(event maintainer.Event) {
+		actions.Observe(event)
+		collector.Observe(event)
+	})
+
+	balancerOpts = append(balancerOpts,
+		maintainer.WithBalancerCodec(codec),
+		maintainer.WithBalancerLogger(logger),
+		maintainer.WithBalancerObserver(observer),
+	)
+
+	fillerOpts := append(conf.Filler.opts(),
+		maintainer.WithFillerCodec(codec),
+		maintainer.WithFillerLogger(logger),
+		maintainer.WithFillerObserver(observer),
+	)
+
+	// The status handler lists the files itself, so it needs the codec
+	// scoped to the topic as well.
+	var topic router.Topic
+	if conf.Topic != "" {
+		topic, err = router.ParseTopic(conf.Topic)
+		if err != nil {
+			return err
+		}
+	}
+	balancerOpts = append(balancerOpts, maintainer.WithBalancerTopic(topic))
+	fillerOpts = append(fillerOpts, maintainer.WithFillerTopic(topic))
+	statusCodec := topic.Codec(codec)
+
+	balancer, err := maintainer.NewBalancer(balancerMetrics, fs, balancerOpts...)
+	if err != nil {
+		return err
+	}
+	balancer.Start()
+	maintainer.StartFiller(fillerMetrics, fs, fillerOpts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mux := http.NewServeMux()
+	(&statusHandler{fs: fs, codec: statusCodec, actions: actions}).register(mux)
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	server := &http.Server{Addr: conf.StatusAddr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	logger.Info("serving status", "addr", conf.StatusAddr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	logger.Info("shutting down")
+	return nil
+}
+
+// newRangeMetrics reads the routers' metrics over the configured transport
+// and turns their running totals into per interval rates. The balancer and
+// filler each get their own Delta so one's reads never shorten the window
+// the other measures over.
+func newRangeMetrics(conf config) (balancer, filler maintainer.RangeMetrics, closer func(), err error) {
+	readerOpts := []metrics.ReaderOpts{
+		metrics.WithTimeout(conf.RouterTimeout.Duration),
+	}
+	if conf.Quorum > 0 {
+		readerOpts = append(readerOpts, metrics.WithQuorum(conf.Quorum))
+	}
+
+	var reader *metrics.Reader
+	switch conf.Transport {
+	case "http":
+		client := metricshttp.NewClient(metricshttp.WithPath(conf.MetricsPath))
+		reader = metrics.NewReader(conf.Routers, client, readerOpts...)
+		closer = func() {}
+	case "grpc":
+		client := metricsgrpc.NewClient(metricsgrpc.WithTimeout(conf.RouterTimeout.Duration))
+		reader = metrics.NewReader(conf.Routers, client, readerOpts...)
+		closer = client.Close
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown transport: %q", conf.Transport)
+	}
+
+	window := conf.DeltaWindow.Duration
+	if window == 0 {
+		window = conf.Balancer.Interval.Duration
+	}
+
+	newDelta := func() maintainer.RangeMetrics {
+		return metrics.NewDelta(conf.DeltaCacheSize, reader, metrics.WithWindow(window))
+	}
+
+	return newDelta(), newDelta(), closer, nil
+}
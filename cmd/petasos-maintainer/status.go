@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/poy/petasos/maintainer"
+	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
+)
+
+// action is a maintainer.Event as reported by /status.
+type action struct {
+	Time   time.Time          `json:"time"`
+	Type   string             `json:"type"`
+	Action string             `json:"action,omitempty"`
+	Ranges []router.RangeName `json:"ranges"`
+	Err    string             `json:"error,omitempty"`
+}
+
+// actionLog is a maintainer.Observer that remembers the most recent
+// actions of the balancer and filler.
+type actionLog struct {
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	actions []action
+}
+
+func newActionLog(size int) *actionLog {
+	return &actionLog{
+		size: size,
+		now:  time.Now,
+	}
+}
+
+func (l *actionLog) Observe(event maintainer.Event) {
+	a := action{Time: l.now()}
+
+	switch e := event.(type) {
+	case maintainer.RangeSeeded:
+		a.Type = "seeded"
+		a.Ranges = []router.RangeName{e.Range}
+	case maintainer.RangeSplit:
+		a.Type = "split"
+		a.Ranges = []router.RangeName{e.Parent, e.Low, e.High}
+	case maintainer.RangesCombined:
+		a.Type = "combined"
+		a.Ranges = []router.RangeName{e.First, e.Next, e.Combined}
+	case maintainer.GapFilled:
+		a.Type = "gap_filled"
+		a.Ranges = []router.RangeName{e.Gap}
+	case maintainer.ActionFailed:
+		a.Type = "failed"
+		a.Action = string(e.Action)
+		a.Ranges = []router.RangeName{e.Range}
+		a.Err = e.Err.Error()
+	default:
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.actions = append(l.actions, a)
+	if len(l.actions) > l.size {
+		l.actions = l.actions[len(l.actions)-l.size:]
+	}
+}
+
+// recent returns the remembered actions, newest first.
+func (l *actionLog) recent() []action {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	actions := make([]action, 0, len(l.actions))
+	for i := len(l.actions) - 1; i >= 0; i-- {
+		actions = append(actions, l.actions[i])
+	}
+	return actions
+}
+
+type status struct {
+	Healthy    bool                `json:"healthy"`
+	Err        string              `json:"error,omitempty"`
+	LastTerm   uint64              `json:"last_term"`
+	Intervals  []topology.Interval `json:"intervals"`
+	Gaps       []topology.Gap      `json:"gaps"`
+	Superseded []topology.Range    `json:"superseded"`
+	Invalid    []string            `json:"invalid"`
+	Actions    []action            `json:"actions"`
+}
+
+// statusHandler serves /healthz and /status for the maintainer.
+type statusHandler struct {
+	fs      maintainer.FileSystem
+	codec   router.RangeNameCodec
+	actions *actionLog
+}
+
+func (h *statusHandler) register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", h.healthz)
+	mux.HandleFunc("/status", h.status)
+}
+
+// healthz reports whether the range files can be listed.
+func (h *statusHandler) healthz(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.fs.List(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Write([]byte("ok\n"))
+}
+
+// status describes the current topology and the last actions taken. A
+// topology that fails topology.Snapshot.Validate is reported as unhealthy.
+func (h *statusHandler) status(w http.ResponseWriter, r *http.Request) {
+	files, err := h.fs.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	snapshot := topology.New(files, h.codec)
+	s := status{
+		Healthy:    true,
+		LastTerm:   snapshot.LastTerm(),
+		Intervals:  snapshot.Intervals(),
+		Gaps:       snapshot.Gaps(),
+		Superseded: snapshot.Superseded(),
+		Invalid:    snapshot.Invalid(),
+		Actions:    h.actions.recent(),
+	}
+
+	if err := snapshot.Validate(); err != nil {
+		s.Healthy = false
+		s.Err = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
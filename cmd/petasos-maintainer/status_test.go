@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/maintainer"
+	"github.com/poy/petasos/router"
+)
+
+type TS struct {
+	*testing.T
+	fs      *stubFileSystem
+	actions *actionLog
+	mux     *http.ServeMux
+}
+
+func TestStatusHandler(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TS {
+		fs := &stubFileSystem{}
+		actions := newActionLog(2)
+		actions.now = func() time.Time { return time.Unix(0, 0).UTC() }
+
+		mux := http.NewServeMux()
+		(&statusHandler{fs: fs, codec: router.JSONCodec{}, actions: actions}).register(mux)
+
+		return TS{
+			T:       t,
+			fs:      fs,
+			actions: actions,
+			mux:     mux,
+		}
+	})
+
+	get := func(t TS, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		t.mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	decode := func(t TS, recorder *httptest.ResponseRecorder) status {
+		var s status
+		if err := json.Unmarshal(recorder.Body.Bytes(), &s); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	o.Spec("healthz reports ok when the files can be listed", func(t TS) {
+		recorder := get(t, "/healthz")
+		Expect(t, recorder.Code).To(Equal(http.StatusOK))
+		Expect(t, recorder.Body.String()).To(Equal("ok\n"))
+	})
+
+	o.Spec("healthz reports unavailable when the files cannot be listed", func(t TS) {
+		t.fs.err = errors.New("some-error")
+
+		recorder := get(t, "/healthz")
+		Expect(t, recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(t, recorder.Body.String()).To(ContainSubstring("some-error"))
+	})
+
+	o.Spec("status describes a healthy topology", func(t TS) {
+		codec := router.JSONCodec{}
+		t.fs.files = []string{
+			codec.Encode(router.RangeName{Low: 0, High: 18446744073709551615, Term: 1}),
+			codec.Encode(router.RangeName{Low: 0, High: 9223372036854775807, Term: 2}),
+			codec.Encode(router.RangeName{Low: 9223372036854775808, High: 18446744073709551615, Term: 3}),
+			"some-file",
+		}
+
+		recorder := get(t, "/status")
+		Expect(t, recorder.Code).To(Equal(http.StatusOK))
+		Expect(t, recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		s := decode(t, recorder)
+		Expect(t, s.Healthy).To(BeTrue())
+		Expect(t, s.LastTerm).To(Equal(uint64(3)))
+		Expect(t, s.Intervals).To(HaveLen(2))
+		Expect(t, s.Gaps).To(HaveLen(0))
+		Expect(t, s.Superseded).To(HaveLen(1))
+		Expect(t, s.Invalid).To(Equal([]string{"some-file"}))
+	})
+
+	o.Spec("status reports an invalid topology as unhealthy", func(t TS) {
+		t.fs.files = []string{
+			router.JSONCodec{}.Encode(router.RangeName{Low: 0, High: 10, Term: 1}),
+		}
+
+		s := decode(t, get(t, "/status"))
+		Expect(t, s.Healthy).To(BeFalse())
+		Expect(t, s.Err).To(ContainSubstring("have no range"))
+		Expect(t, s.Gaps).To(HaveLen(1))
+	})
+
+	o.Spec("status lists the most recent actions first", func(t TS) {
+		t.actions.Observe(maintainer.RangeSeeded{Range: router.RangeName{Term: 1}})
+		t.actions.Observe(maintainer.GapFilled{Gap: router.RangeName{Term: 2}})
+		t.actions.Observe(maintainer.ActionFailed{
+			Action: maintainer.ActionSplit,
+			Range:  router.RangeName{Term: 3},
+			Err:    errors.New("some-error"),
+		})
+
+		s := decode(t, get(t, "/status"))
+		Expect(t, s.Actions).To(Equal([]action{
+			{
+				Time:   time.Unix(0, 0).UTC(),
+				Type:   "failed",
+				Action: "split",
+				Ranges: []router.RangeName{{Term: 3}},
+				Err:    "some-error",
+			},
+			{
+				Time:   time.Unix(0, 0).UTC(),
+				Type:   "gap_filled",
+				Ranges: []router.RangeName{{Term: 2}},
+			},
+		}))
+	})
+
+	o.Spec("status reports unavailable when the files cannot be listed", func(t TS) {
+		t.fs.err = errors.New("some-error")
+
+		recorder := get(t, "/status")
+		Expect(t, recorder.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+}
+
+type stubFileSystem struct {
+	files []string
+	err   error
+}
+
+func (s *stubFileSystem) Create(file string) error {
+	return nil
+}
+
+func (s *stubFileSystem) List() ([]string, error) {
+	return s.files, s.err
+}
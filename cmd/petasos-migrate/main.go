@@ -0,0 +1,74 @@
+// petasos-migrate renames the range files in a directory from one codec to
+// another. Giving the same codec for -from and -to renames files to the name
+// the codec encodes (e.g., JSON with its fields in another order). Stop
+// every router, reader and maintainer using the directory before running
+// it.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/poy/petasos/localfs"
+	"github.com/poy/petasos/migrate"
+	"github.com/poy/petasos/router"
+)
+
+func main() {
+	dir := flag.String("dir", "", "directory holding the range files")
+	from := flag.String("from", "json", "current codec (json, hex or path)")
+	to := flag.String("to", "hex", "new codec (json, hex or path)")
+	topic := flag.String("topic", "", "topic of the range files (optional)")
+	dryRun := flag.Bool("dry-run", false, "print the renames without making them")
+	flag.Parse()
+
+	if *dir == "" {
+		log.Fatal("-dir is required")
+	}
+
+	fromCodec, err := router.ParseCodec(*from)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	toCodec, err := router.ParseCodec(*to)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *topic != "" {
+		if fromCodec, err = router.NewTopicCodec(*topic, fromCodec); err != nil {
+			log.Fatal(err)
+		}
+
+		if toCodec, err = router.NewTopicCodec(*topic, toCodec); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fs, err := localfs.New(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *dryRun {
+		renames, err := migrate.Plan(fs, fromCodec, toCodec)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, r := range renames {
+			log.Printf("%s -> %s", r.From, r.To)
+		}
+		return
+	}
+
+	done, err := migrate.Run(fs, fromCodec, toCodec)
+	for _, r := range done {
+		log.Printf("%s -> %s", r.From, r.To)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,159 @@
+// petasos-reader streams the range files in a directory to consumers over
+// HTTP (as server-sent events) and gRPC.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/poy/petasos/internal/storage"
+	"github.com/poy/petasos/reader"
+	readergrpc "github.com/poy/petasos/reader/grpc"
+	"github.com/poy/petasos/reader/grpc/readerpb"
+	readerhttp "github.com/poy/petasos/reader/http"
+	"github.com/poy/petasos/router"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory holding the range files")
+	nodes := flag.String("storage", "", "comma separated petasos-storage nodes to use instead of -dir (several are replicated to)")
+	writeQuorum := flag.Int("write-quorum", 0, "storage nodes that must accept each write (0 for a majority)")
+	codecName := flag.String("codec", "json", "range name codec (json, hex or path)")
+	topic := flag.String("topic", "", "topic of the range set (optional)")
+	httpAddr := flag.String("http-addr", ":8082", "address to serve HTTP on (empty to disable)")
+	grpcAddr := flag.String("grpc-addr", ":9092", "address to serve gRPC on (empty to disable)")
+	readPath := flag.String("read-path", "/read", "path to serve HTTP reads on")
+	interval := flag.Duration("interval", 250*time.Millisecond, "how often a following read checks for new data")
+	maxInterval := flag.Duration("max-interval", 5*time.Second, "how far a following read backs off while there is no new data")
+	flag.Parse()
+
+	logger := slog.Default()
+
+	if err := run(logger, config{
+		dir:         *dir,
+		nodes:       storage.Nodes(*nodes),
+		writeQuorum: *writeQuorum,
+		codecName:   *codecName,
+		topic:       *topic,
+		httpAddr:    *httpAddr,
+		grpcAddr:    *grpcAddr,
+		readPath:    *readPath,
+		interval:    *interval,
+		maxInterval: *maxInterval,
+	}); err != nil {
+		logger.Error("reader failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	dir                string
+	nodes              []string
+	writeQuorum        int
+	codecName, topic   string
+	httpAddr, grpcAddr string
+	readPath           string
+	interval           time.Duration
+	maxInterval        time.Duration
+}
+
+func run(logger *slog.Logger, conf config) error {
+	codec, err := router.ParseCodec(conf.codecName)
+	if err != nil {
+		return err
+	}
+
+	fs, closeFS, err := storage.Open(conf.dir, conf.nodes, conf.writeQuorum, logger)
+	if err != nil {
+		return err
+	}
+	defer closeFS()
+
+	opts := []reader.RouteReaderOpts{
+		reader.WithCodec(codec),
+		reader.WithLogger(logger),
+	}
+	if conf.topic != "" {
+		topic, err := router.ParseTopic(conf.topic)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, reader.WithTopic(topic))
+	}
+
+	r := reader.NewRouteReader(fs, opts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 2)
+
+	if conf.httpAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle(conf.readPath, readerhttp.NewHandler(r,
+			readerhttp.WithPollInterval(conf.interval),
+			readerhttp.WithMaxPollInterval(conf.maxInterval),
+		))
+
+		server := &http.Server{
+			Addr:    conf.httpAddr,
+			Handler: mux,
+			// Following reads never end on their own.
+			BaseContext: func(net.Listener) context.Context { return ctx },
+		}
+		go func() {
+			<-ctx.Done()
+			server.Shutdown(context.Background())
+		}()
+
+		go func() {
+			logger.Info("serving HTTP", "addr", conf.httpAddr)
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+	}
+
+	if conf.grpcAddr != "" {
+		lis, err := net.Listen("tcp", conf.grpcAddr)
+		if err != nil {
+			return err
+		}
+
+		server := grpc.NewServer()
+		readerpb.RegisterReaderServer(server, readergrpc.NewServer(r,
+			readergrpc.WithPollInterval(conf.interval),
+			readergrpc.WithMaxPollInterval(conf.maxInterval),
+		))
+
+		go func() {
+			<-ctx.Done()
+			// GracefulStop would wait on following reads forever.
+			server.Stop()
+		}()
+
+		go func() {
+			logger.Info("serving gRPC", "addr", lis.Addr().String())
+			if err := server.Serve(lis); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down")
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
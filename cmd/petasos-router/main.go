@@ -0,0 +1,181 @@
+// petasos-router accepts writes over HTTP and gRPC and routes them to the
+// range files in a directory. It serves its metrics for the maintainer on
+// both as well.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/poy/petasos/internal/storage"
+	metricsgrpc "github.com/poy/petasos/metrics/grpc"
+	"github.com/poy/petasos/metrics/grpc/metricspb"
+	metricshttp "github.com/poy/petasos/metrics/http"
+	"github.com/poy/petasos/router"
+	routergrpc "github.com/poy/petasos/router/grpc"
+	"github.com/poy/petasos/router/grpc/routerpb"
+	routerhttp "github.com/poy/petasos/router/http"
+	"google.golang.org/grpc"
+)
+
+var hashers = map[string]router.Hasher{
+	"fnv": router.FNVHasher{},
+}
+
+func main() {
+	dir := flag.String("dir", ".", "directory holding the range files")
+	node := flag.String("storage", "", "petasos-storage node to write to instead of -dir (use its -replicas to replicate)")
+	codecName := flag.String("codec", "json", "range name codec (json, hex or path)")
+	topic := flag.String("topic", "", "topic of the range set (optional)")
+	hasherName := flag.String("hasher", "fnv", "how to hash writes (fnv)")
+	httpAddr := flag.String("http-addr", ":8080", "address to serve HTTP on (empty to disable)")
+	grpcAddr := flag.String("grpc-addr", ":9090", "address to serve gRPC on (empty to disable)")
+	writePath := flag.String("write-path", "/write", "path to accept HTTP writes on")
+	metricsPath := flag.String("metrics-path", "/metrics", "path to serve HTTP metrics on")
+	maxSize := flag.Int64("max-size", 1024*1024, "largest HTTP write accepted in bytes")
+	refresh := flag.Duration("refresh-interval", 5*time.Second, "how often to list the ranges again")
+	flag.Parse()
+
+	logger := slog.Default()
+
+	if err := run(logger, config{
+		dir:         *dir,
+		nodes:       storage.Nodes(*node),
+		codecName:   *codecName,
+		topic:       *topic,
+		hasherName:  *hasherName,
+		httpAddr:    *httpAddr,
+		grpcAddr:    *grpcAddr,
+		writePath:   *writePath,
+		metricsPath: *metricsPath,
+		maxSize:     *maxSize,
+		refresh:     *refresh,
+	}); err != nil {
+		logger.Error("router failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	dir                          string
+	nodes                        []string
+	codecName, topic, hasherName string
+	httpAddr, grpcAddr           string
+	writePath, metricsPath       string
+	maxSize                      int64
+	refresh                      time.Duration
+}
+
+func run(logger *slog.Logger, conf config) error {
+	codec, err := router.ParseCodec(conf.codecName)
+	if err != nil {
+		return err
+	}
+
+	hasher, ok := hashers[conf.hasherName]
+	if !ok {
+		return fmt.Errorf("unknown hasher: %q", conf.hasherName)
+	}
+
+	// Each file must have a single writer for its replicas to agree on the
+	// order of its records (see package replicafs), so routers share a
+	// replicating petasos-storage node instead of replicating on their own.
+	// Writes fail while that node is down (see petasos-storage).
+	if len(conf.nodes) > 1 {
+		return fmt.Errorf("-storage takes a single node, got %d: replicate with petasos-storage -replicas", len(conf.nodes))
+	}
+
+	fs, closeFS, err := storage.Open(conf.dir, conf.nodes, 0, logger)
+	if err != nil {
+		return err
+	}
+	defer closeFS()
+
+	opts := []router.RouterOpts{
+		router.WithCodec(codec),
+		router.WithRefreshInterval(conf.refresh),
+		router.WithLogger(logger),
+	}
+
+	// The metrics are requested by file name, so they need the codec scoped
+	// to the topic as well.
+	var topic router.Topic
+	if conf.topic != "" {
+		topic, err = router.ParseTopic(conf.topic)
+		if err != nil {
+			return err
+		}
+	}
+	opts = append(opts, router.WithTopic(topic))
+	metricsCodec := topic.Codec(codec)
+
+	counter := router.NewCounter()
+	r := router.New(fs, hasher, counter, opts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 2)
+
+	if conf.httpAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle(conf.writePath, routerhttp.NewHandler(r,
+			routerhttp.WithMaxSize(conf.maxSize),
+			routerhttp.WithLogger(logger),
+		))
+		mux.Handle(conf.metricsPath, metricshttp.NewHandler(counter, metricshttp.WithCodec(metricsCodec)))
+
+		server := &http.Server{Addr: conf.httpAddr, Handler: mux}
+		go func() {
+			<-ctx.Done()
+			server.Shutdown(context.Background())
+		}()
+
+		go func() {
+			logger.Info("serving HTTP", "addr", conf.httpAddr)
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+	}
+
+	if conf.grpcAddr != "" {
+		lis, err := net.Listen("tcp", conf.grpcAddr)
+		if err != nil {
+			return err
+		}
+
+		server := grpc.NewServer()
+		routerpb.RegisterRouterServer(server, routergrpc.NewServer(r))
+		metricspb.RegisterMetricsServer(server, metricsgrpc.NewServer(counter, metricsgrpc.WithCodec(metricsCodec)))
+
+		go func() {
+			<-ctx.Done()
+			server.GracefulStop()
+		}()
+
+		go func() {
+			logger.Info("serving gRPC", "addr", lis.Addr().String())
+			if err := server.Serve(lis); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down")
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
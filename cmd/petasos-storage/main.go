@@ -0,0 +1,71 @@
+// petasos-storage serves the range files in a directory over gRPC so
+// routers, readers and maintainers can run on other nodes (see their
+// -storage flag). With -replicas it instead replicates to other storage
+// nodes, so routers sharing it agree on the order of each file's records.
+// Only one node should replicate to a set of replicas.
+//
+// That node is a single point of failure: while it is down, nothing can be
+// written even though the replicas are up. Run it under a supervisor that
+// restarts it (on another host if need be); a restarted node catches the
+// replicas up before writing again.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/poy/petasos/internal/storage"
+	"github.com/poy/petasos/remotefs"
+	"github.com/poy/petasos/remotefs/remotefspb"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory holding the range files")
+	addr := flag.String("addr", ":9091", "address to serve gRPC on")
+	replicas := flag.String("replicas", "", "comma separated petasos-storage nodes to replicate to instead of serving -dir")
+	writeQuorum := flag.Int("write-quorum", 0, "replicas that must accept each write (0 for a majority)")
+	flag.Parse()
+
+	logger := slog.Default()
+
+	if err := run(logger, *dir, *addr, storage.Nodes(*replicas), *writeQuorum); err != nil {
+		logger.Error("storage failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(logger *slog.Logger, dir, addr string, replicas []string, writeQuorum int) error {
+	fs, closeFS, err := storage.Open(dir, replicas, writeQuorum, logger)
+	if err != nil {
+		return err
+	}
+	defer closeFS()
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := grpc.NewServer()
+	remotefspb.RegisterFileSystemServer(server, remotefs.NewServer(fs))
+
+	go func() {
+		<-ctx.Done()
+		logger.Info("shutting down")
+		// Writers stay open for as long as the routers run, so
+		// GracefulStop would never return.
+		server.Stop()
+	}()
+
+	logger.Info("serving gRPC", "addr", lis.Addr().String())
+	return server.Serve(lis)
+}
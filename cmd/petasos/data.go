@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/router"
+)
+
+func tail(s store, args []string) error {
+	fset := flag.NewFlagSet("tail", flag.ExitOnError)
+	hash := fset.Uint64("hash", 0, "hash to read")
+	follow := fset.Bool("follow", false, "wait for new data")
+	interval := fset.Duration("interval", 250*time.Millisecond, "how often to check for new data with -follow")
+	fset.Parse(args)
+
+	r := reader.NewRouteReader(s.fs,
+		reader.WithCodec(s.codec),
+		reader.WithTopic(s.topic),
+	).ReadFrom(*hash)
+
+	for {
+		data, err := r.Read()
+		if err == io.EOF {
+			if !*follow {
+				return nil
+			}
+
+			time.Sleep(*interval)
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s\n", data.Payload)
+	}
+}
+
+func write(s store, args []string) error {
+	fset := flag.NewFlagSet("write", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: petasos write [-hash hash] [data...]")
+		fmt.Fprintln(fset.Output(), "Each argument, or each line of stdin, is written.")
+		fset.PrintDefaults()
+	}
+	hash := fset.String("hash", "", "hash to write to instead of hashing the data with FNV-1a")
+	fset.Parse(args)
+
+	var hasher router.Hasher = router.FNVHasher{}
+	if *hash != "" {
+		var h fixedHasher
+		if _, err := fmt.Sscan(*hash, &h); err != nil {
+			return fmt.Errorf("invalid hash: %s", *hash)
+		}
+		hasher = h
+	}
+
+	r := router.New(s.fs, hasher, router.NewCounter(),
+		router.WithCodec(s.codec),
+		router.WithTopic(s.topic),
+	)
+
+	if fset.NArg() > 0 {
+		for _, data := range fset.Args() {
+			if err := r.Write([]byte(data)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if err := r.Write(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// fixedHasher routes all data to the same hash.
+type fixedHasher uint64
+
+func (h fixedHasher) Hash(data []byte) (hash uint64, err error) {
+	return uint64(h), nil
+}
@@ -0,0 +1,111 @@
+// petasos inspects and operates a petasos store on local disk.
+//
+// Usage:
+//
+//	petasos [-dir dir] [-codec json|hex|path] [-topic topic] <command> [flags]
+//
+// Commands:
+//
+//	ranges   list the effective topology
+//	gaps     list the hashes without a range
+//	metrics  query routers for each range's metrics
+//	split    split a range in two
+//	combine  combine two adjacent ranges
+//	tail     read the data for a hash
+//	write    route data to the ranges
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/poy/petasos/localfs"
+	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
+)
+
+type command func(s store, args []string) error
+
+var commands = map[string]command{
+	"ranges":  ranges,
+	"gaps":    gaps,
+	"metrics": metrics,
+	"split":   split,
+	"combine": combine,
+	"tail":    tail,
+	"write":   write,
+}
+
+// store is the store every command operates on. rangeCodec is the codec
+// scoped to the topic, if there is one.
+type store struct {
+	fs         *localfs.FileSystem
+	codec      router.RangeNameCodec
+	rangeCodec router.RangeNameCodec
+	topic      router.Topic
+}
+
+func main() {
+	log.SetFlags(0)
+
+	dir := flag.String("dir", ".", "directory holding the range files")
+	codecName := flag.String("codec", "json", "range name codec (json, hex or path)")
+	topic := flag.String("topic", "", "topic of the range set (optional)")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		log.Printf("unknown command: %s", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	codec, err := router.ParseCodec(*codecName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fs, err := localfs.New(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := store{
+		fs:    fs,
+		codec: codec,
+	}
+
+	if *topic != "" {
+		s.topic, err = router.ParseTopic(*topic)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	s.rangeCodec = s.topic.Codec(codec)
+
+	if err := cmd(s, flag.Args()[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: petasos [flags] <ranges|gaps|metrics|split|combine|tail|write> [flags]\n")
+	flag.PrintDefaults()
+}
+
+func (s store) snapshot() (*topology.Snapshot, error) {
+	list, err := s.fs.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return topology.New(list, s.rangeCodec), nil
+}
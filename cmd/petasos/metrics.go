@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	petasosmetrics "github.com/poy/petasos/metrics"
+	metricsgrpc "github.com/poy/petasos/metrics/grpc"
+	metricshttp "github.com/poy/petasos/metrics/http"
+)
+
+func metrics(s store, args []string) error {
+	fset := flag.NewFlagSet("metrics", flag.ExitOnError)
+	routers := fset.String("routers", "", "comma separated router addresses")
+	transport := fset.String("transport", "http", "how to reach the routers (http or grpc)")
+	timeout := fset.Duration("timeout", 5*time.Second, "timeout for each router")
+	fset.Parse(args)
+
+	if *routers == "" {
+		return fmt.Errorf("-routers is required")
+	}
+
+	var network petasosmetrics.NetworkReader
+	switch *transport {
+	case "http":
+		network = metricshttp.NewClient()
+	case "grpc":
+		client := metricsgrpc.NewClient(metricsgrpc.WithTimeout(*timeout))
+		defer client.Close()
+		network = client
+	default:
+		return fmt.Errorf("unknown transport: %s", *transport)
+	}
+
+	reader := petasosmetrics.NewReader(
+		strings.Split(*routers, ","),
+		network,
+		petasosmetrics.WithTimeout(*timeout),
+	)
+
+	snapshot, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LOW\tHIGH\tTERM\tWRITES\tERRORS\tBYTES\tP50\tP99\tMISSING")
+
+	seen := make(map[string]bool)
+	for _, i := range snapshot.Intervals() {
+		if seen[i.Owner.File] {
+			continue
+		}
+		seen[i.Owner.File] = true
+
+		m, missing, err := reader.PartialMetrics(i.Owner.File)
+		if err != nil {
+			return err
+		}
+
+		rn := i.Owner.Name
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			rn.Low, rn.High, rn.Term,
+			m.WriteCount, m.ErrCount, m.ByteCount,
+			m.Latency.P50(), m.Latency.P99(),
+			strings.Join(missing, ","),
+		)
+	}
+
+	return w.Flush()
+}
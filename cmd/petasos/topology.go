@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/poy/petasos/maintainer"
+	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
+)
+
+func ranges(s store, args []string) error {
+	fset := flag.NewFlagSet("ranges", flag.ExitOnError)
+	all := fset.Bool("all", false, "include superseded and invalid files")
+	fset.Parse(args)
+
+	snapshot, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LOW\tHIGH\tTERM\tFILE")
+	for _, i := range snapshot.Intervals() {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\n", i.Low, i.High, i.Owner.Name.Term, i.Owner.File)
+	}
+
+	if *all {
+		for _, r := range snapshot.Superseded() {
+			fmt.Fprintf(w, "%d\t%d\t%d\t%s (superseded)\n", r.Name.Low, r.Name.High, r.Name.Term, r.File)
+		}
+
+		for _, file := range snapshot.Invalid() {
+			fmt.Fprintf(w, "-\t-\t-\t%s (invalid)\n", file)
+		}
+	}
+
+	return w.Flush()
+}
+
+func gaps(s store, args []string) error {
+	fset := flag.NewFlagSet("gaps", flag.ExitOnError)
+	fset.Parse(args)
+
+	snapshot, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LOW\tHIGH")
+	for _, g := range snapshot.Gaps() {
+		fmt.Fprintf(w, "%d\t%d\n", g.Low, g.High)
+	}
+
+	return w.Flush()
+}
+
+func split(s store, args []string) error {
+	fset := flag.NewFlagSet("split", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: petasos split <file>")
+	}
+	fset.Parse(args)
+
+	if fset.NArg() != 1 {
+		fset.Usage()
+		os.Exit(2)
+	}
+
+	snapshot, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+
+	parent, err := find(snapshot, fset.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err := maintainer.ValidateChange(parent.Name, snapshot, parent.File); err != nil {
+		return err
+	}
+
+	low, high := maintainer.SplitRange(parent.Name, snapshot.LastTerm())
+	return s.create(low, high)
+}
+
+func combine(s store, args []string) error {
+	fset := flag.NewFlagSet("combine", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "Usage: petasos combine <file> <file>")
+	}
+	fset.Parse(args)
+
+	if fset.NArg() != 2 {
+		fset.Usage()
+		os.Exit(2)
+	}
+
+	snapshot, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+
+	x, err := find(snapshot, fset.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	y, err := find(snapshot, fset.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	combined, err := maintainer.CombineRanges(x.Name, y.Name, snapshot.LastTerm())
+	if err != nil {
+		return err
+	}
+
+	if err := maintainer.ValidateChange(combined, snapshot, x.File, y.File); err != nil {
+		return err
+	}
+
+	return s.create(combined)
+}
+
+func (s store) create(rns ...router.RangeName) error {
+	codec := s.rangeCodec
+	for _, rn := range rns {
+		file := codec.Encode(rn)
+		if err := s.fs.Create(file); err != nil {
+			return err
+		}
+		fmt.Println(file)
+	}
+
+	return nil
+}
+
+func find(snapshot *topology.Snapshot, file string) (topology.Range, error) {
+	for _, r := range snapshot.Ranges() {
+		if r.File == file {
+			return r, nil
+		}
+	}
+
+	return topology.Range{}, fmt.Errorf("unknown range: %s", file)
+}
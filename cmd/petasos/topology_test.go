@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/localfs"
+	"github.com/poy/petasos/router"
+)
+
+type TT struct {
+	*testing.T
+	s store
+}
+
+func TestTopologyCommands(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TT {
+		fs, err := localfs.New(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return TT{
+			T: t,
+			s: store{fs: fs, codec: router.JSONCodec{}, rangeCodec: router.JSONCodec{}},
+		}
+	})
+
+	create := func(t TT, low, high, term uint64) string {
+		file := router.JSONCodec{}.Encode(router.RangeName{Low: low, High: high, Term: term})
+		if err := t.s.fs.Create(file); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	owners := func(t TT) []router.RangeName {
+		snapshot, err := t.s.snapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []router.RangeName
+		for _, i := range snapshot.Intervals() {
+			names = append(names, router.RangeName{Low: i.Low, High: i.High, Term: i.Owner.Name.Term})
+		}
+		return names
+	}
+
+	o.Spec("split replaces the range with its halves", func(t TT) {
+		file := create(t, 0, 18446744073709551615, 1)
+
+		Expect(t, split(t.s, []string{file})).To(BeNil())
+		Expect(t, owners(t)).To(Equal([]router.RangeName{
+			{Low: 0, High: 9223372036854775807, Term: 2},
+			{Low: 9223372036854775808, High: 18446744073709551615, Term: 3},
+		}))
+	})
+
+	o.Spec("split refuses to cover a newer range", func(t TT) {
+		file := create(t, 0, 18446744073709551615, 1)
+		create(t, 0, 100, 2)
+
+		Expect(t, split(t.s, []string{file})).To(Not(BeNil()))
+		Expect(t, owners(t)).To(HaveLen(2))
+	})
+
+	o.Spec("split returns an error for an unknown range", func(t TT) {
+		Expect(t, split(t.s, []string{"some-file"})).To(Not(BeNil()))
+	})
+
+	o.Spec("combine replaces adjacent ranges with one", func(t TT) {
+		x := create(t, 0, 9223372036854775807, 1)
+		y := create(t, 9223372036854775808, 18446744073709551615, 2)
+
+		Expect(t, combine(t.s, []string{x, y})).To(BeNil())
+		Expect(t, owners(t)).To(Equal([]router.RangeName{
+			{Low: 0, High: 18446744073709551615, Term: 3},
+		}))
+	})
+
+	o.Spec("combine refuses ranges that are not adjacent", func(t TT) {
+		x := create(t, 0, 100, 1)
+		create(t, 101, 200, 2)
+		y := create(t, 201, 18446744073709551615, 3)
+
+		Expect(t, combine(t.s, []string{x, y})).To(Not(BeNil()))
+		Expect(t, owners(t)).To(HaveLen(3))
+	})
+
+	o.Spec("combine refuses to cover a newer range", func(t TT) {
+		x := create(t, 0, 9223372036854775807, 1)
+		y := create(t, 9223372036854775808, 18446744073709551615, 2)
+		create(t, 0, 100, 3)
+
+		Expect(t, combine(t.s, []string{x, y})).To(Not(BeNil()))
+		Expect(t, owners(t)).To(HaveLen(3))
+	})
+}
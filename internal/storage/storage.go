@@ -0,0 +1,83 @@
+// Package storage opens the FileSystem the petasos binaries run against.
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/poy/petasos/localfs"
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/remotefs"
+	"github.com/poy/petasos/replicafs"
+	"github.com/poy/petasos/router"
+)
+
+// FileSystem implements the router, reader and maintainer FileSystems.
+type FileSystem interface {
+	List() (file []string, err error)
+	Create(file string) (err error)
+	Writer(name string) (writer router.Writer, err error)
+	Reader(name string, startingIndex uint64) (reader reader.Reader, err error)
+	Len(name string) (length uint64, err error)
+}
+
+// Open returns the directory's FileSystem if no storage nodes are given. A
+// single node is used directly and several are replicated to with the
+// write quorum (0 for a majority). It returns an error if the write quorum
+// is negative or above the number of nodes.
+func Open(dir string, nodes []string, writeQuorum int, logger *slog.Logger) (fs FileSystem, closeFS func(), err error) {
+	if writeQuorum < 0 || writeQuorum > max(len(nodes), 1) {
+		return nil, nil, fmt.Errorf("write quorum %d is out of range for %d storage nodes", writeQuorum, len(nodes))
+	}
+
+	if len(nodes) == 0 {
+		fs, err := localfs.New(dir)
+		return fs, func() {}, err
+	}
+
+	var clients []*remotefs.Client
+	closeAll := func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}
+
+	for _, node := range nodes {
+		client, err := remotefs.NewClient(node)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		clients = append(clients, client)
+	}
+
+	if len(clients) == 1 {
+		return clients[0], closeAll, nil
+	}
+
+	backends := make([]replicafs.Backend, 0, len(clients))
+	for _, c := range clients {
+		backends = append(backends, c)
+	}
+
+	fs, err = replicafs.New(backends,
+		replicafs.WithWriteQuorum(writeQuorum),
+		replicafs.WithLogger(logger),
+	)
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+
+	return fs, closeAll, nil
+}
+
+// Nodes splits a comma separated list of storage nodes.
+func Nodes(list string) []string {
+	if list == "" {
+		return nil
+	}
+
+	return strings.Split(list, ",")
+}
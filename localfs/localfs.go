@@ -0,0 +1,417 @@
+// Package localfs stores range files in a directory on local disk. It
+// implements the router, reader and maintainer FileSystems.
+//
+// Each file is a sequence of records, each a 4 byte big-endian length
+// followed by the payload. A record's index is its position in the file.
+//
+// Once a range is superseded by newer ones its file is made read-only and
+// no more records are written to it, so readers can tell when they have
+// read all of it.
+package localfs
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
+)
+
+// ErrSuperseded is returned when writing to a range that newer ranges have
+// superseded.
+var ErrSuperseded = errors.New("range is superseded")
+
+type FileSystem struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]*fileWriter
+}
+
+// New returns a FileSystem rooted at dir, creating it if necessary.
+func New(dir string) (*FileSystem, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &FileSystem{
+		dir:   dir,
+		files: make(map[string]*fileWriter),
+	}, nil
+}
+
+// List returns every file under the directory. Files in sub-directories
+// (e.g., topics) are named with a slash separated path.
+func (f *FileSystem) List() (file []string, err error) {
+	err = filepath.WalkDir(f.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(f.dir, path)
+		if err != nil {
+			return err
+		}
+
+		file = append(file, filepath.ToSlash(rel))
+		return nil
+	})
+
+	return file, err
+}
+
+// Create creates an empty file. It returns an error if the file exists.
+// The ranges in the same directory that the new one supersedes are
+// retired.
+func (f *FileSystem) Create(file string) (err error) {
+	path, err := f.path("create", file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return f.retire(file)
+}
+
+// retire makes the files of the superseded ranges in the file's directory
+// read-only. Writers check for that before each write, including those in
+// other processes.
+func (f *FileSystem) retire(file string) error {
+	dir := path.Dir(file)
+	entries, err := os.ReadDir(filepath.Join(f.dir, filepath.FromSlash(dir)))
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+
+	for _, rng := range topology.New(names, router.AnyCodec{}).Superseded() {
+		name := path.Join(dir, rng.File)
+
+		f.mu.Lock()
+		w := f.files[name]
+		f.mu.Unlock()
+
+		if w != nil {
+			w.mu.Lock()
+			w.superseded = true
+			w.mu.Unlock()
+		}
+
+		if err := os.Chmod(filepath.Join(f.dir, filepath.FromSlash(name)), 0444); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rename renames a file. It is used to migrate between codecs.
+func (f *FileSystem) Rename(from, to string) (err error) {
+	fromPath, err := f.path("rename", from)
+	if err != nil {
+		return err
+	}
+
+	path, err := f.path("rename", to)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.Rename(fromPath, path)
+}
+
+// Writer appends records to an existing file. Every Writer for a file
+// shares one handle until they are all closed.
+func (f *FileSystem) Writer(name string) (writer router.Writer, err error) {
+	path, err := f.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w, ok := f.files[name]
+	if !ok {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		w = &fileWriter{fs: f, name: name, file: file}
+		f.files[name] = w
+	}
+	w.refs++
+
+	return &writerHandle{w: w}, nil
+}
+
+// release closes the file once every Writer for it has been closed.
+func (f *FileSystem) release(w *fileWriter) {
+	f.mu.Lock()
+	w.refs--
+	last := w.refs == 0
+	if last {
+		delete(f.files, w.name)
+	}
+	f.mu.Unlock()
+
+	if last {
+		w.file.Close()
+	}
+}
+
+// Len returns how many complete records the file has. It reads each
+// record's length, not its payload. While the file has Writers, only the
+// records appended since the last Len are counted.
+func (f *FileSystem) Len(name string) (length uint64, err error) {
+	path, err := f.path("open", name)
+	if err != nil {
+		return 0, err
+	}
+
+	f.mu.Lock()
+	w, ok := f.files[name]
+	if ok {
+		w.refs++
+	}
+	f.mu.Unlock()
+
+	if !ok {
+		length, _, err := count(path, 0)
+		return length, err
+	}
+	defer f.release(w)
+
+	return w.len(path)
+}
+
+// Reader reads the file's records starting at startingIndex. Read returns
+// io.EOF once it has read every complete record.
+func (f *FileSystem) Reader(name string, startingIndex uint64) (r reader.Reader, err error) {
+	path, err := f.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fr := &fileReader{
+		name: name,
+		file: file,
+		r:    bufio.NewReader(file),
+	}
+
+	for fr.index < startingIndex {
+		if _, err := fr.next(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	return fr, nil
+}
+
+// path returns where the file is stored. Names that are absolute, contain
+// "." or ".." elements or a backslash are rejected so that a file can not
+// escape the directory.
+func (f *FileSystem) path(op, file string) (string, error) {
+	if file == "." || !fs.ValidPath(file) || strings.Contains(file, `\`) {
+		return "", &fs.PathError{Op: op, Path: file, Err: fs.ErrInvalid}
+	}
+
+	return filepath.Join(f.dir, filepath.FromSlash(file)), nil
+}
+
+// fileWriter is the handle shared by a file's Writers.
+type fileWriter struct {
+	fs   *FileSystem
+	name string
+	refs int
+
+	mu         sync.Mutex
+	file       *os.File
+	superseded bool
+
+	// length is the number of complete records before offset.
+	length uint64
+	offset int64
+}
+
+func (w *fileWriter) len(path string) (length uint64, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Records are only appended, so those before offset are unchanged.
+	n, offset, err := count(path, w.offset)
+	if err != nil {
+		return 0, err
+	}
+	w.length += n
+	w.offset = offset
+
+	return w.length, nil
+}
+
+func (w *fileWriter) write(data []byte) (err error) {
+	record := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(record, uint32(len(data)))
+	copy(record[4:], data)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Another process may have retired the file.
+	if !w.superseded {
+		info, err := w.file.Stat()
+		if err != nil {
+			return err
+		}
+		w.superseded = info.Mode().Perm()&0200 == 0
+	}
+
+	if w.superseded {
+		return &fs.PathError{Op: "write", Path: w.name, Err: ErrSuperseded}
+	}
+
+	// A single write keeps each record intact when appending.
+	_, err = w.file.Write(record)
+	return err
+}
+
+// writerHandle is the router.Writer for a shared fileWriter.
+type writerHandle struct {
+	w    *fileWriter
+	once sync.Once
+}
+
+func (h *writerHandle) Write(data []byte) (err error) {
+	return h.w.write(data)
+}
+
+func (h *writerHandle) Close() {
+	h.once.Do(func() {
+		h.w.fs.release(h.w)
+	})
+}
+
+type fileReader struct {
+	name  string
+	file  *os.File
+	r     *bufio.Reader
+	index uint64
+}
+
+func (r *fileReader) Read() (data reader.DataPacket, err error) {
+	idx := r.index
+	payload, err := r.next()
+	if err != nil {
+		return reader.DataPacket{}, err
+	}
+
+	return reader.DataPacket{
+		Payload:  payload,
+		Filename: r.name,
+		Index:    idx,
+	}, nil
+}
+
+// next reads the next record. A partially written record is treated as
+// the end of the file.
+func (r *fileReader) next() (payload []byte, err error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return nil, eof(err)
+	}
+
+	payload = make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err := io.ReadFull(r.r, payload); err != nil {
+		return nil, eof(err)
+	}
+
+	r.index++
+	return payload, nil
+}
+
+func (r *fileReader) Close() {
+	r.file.Close()
+}
+
+// count returns how many complete records the file has from offset, which
+// must be where a record starts, and where the last of them ends. It reads
+// each record's length and skips its payload.
+func count(path string, offset int64) (length uint64, end int64, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var header [4]byte
+	for offset+4 <= info.Size() {
+		if _, err := file.ReadAt(header[:], offset); err != nil {
+			return 0, 0, err
+		}
+
+		next := offset + 4 + int64(binary.BigEndian.Uint32(header[:]))
+		if next > info.Size() {
+			break
+		}
+
+		length++
+		offset = next
+	}
+
+	return length, offset, nil
+}
+
+func eof(err error) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return io.EOF
+	}
+
+	return err
+}
@@ -0,0 +1,227 @@
+package localfs_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/localfs"
+	"github.com/poy/petasos/maintainer"
+	"github.com/poy/petasos/migrate"
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/router"
+)
+
+var (
+	_ router.FileSystem     = &localfs.FileSystem{}
+	_ reader.FileSystem     = &localfs.FileSystem{}
+	_ maintainer.FileSystem = &localfs.FileSystem{}
+	_ migrate.FileSystem    = &localfs.FileSystem{}
+)
+
+type TL struct {
+	*testing.T
+
+	dir string
+	fs  *localfs.FileSystem
+}
+
+func TestFileSystem(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TL {
+		dir := t.TempDir()
+		fs, err := localfs.New(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return TL{
+			T:   t,
+			dir: dir,
+			fs:  fs,
+		}
+	})
+
+	o.Spec("it lists created files", func(t TL) {
+		Expect(t, t.fs.Create("a")).To(BeNil())
+		Expect(t, t.fs.Create("some-topic/b")).To(BeNil())
+
+		files, err := t.fs.List()
+		Expect(t, err == nil).To(BeTrue())
+		sort.Strings(files)
+		Expect(t, files).To(Equal([]string{"a", "some-topic/b"}))
+	})
+
+	o.Spec("it does not create a file twice", func(t TL) {
+		Expect(t, t.fs.Create("a")).To(BeNil())
+		Expect(t, t.fs.Create("a") == nil).To(BeFalse())
+	})
+
+	o.Spec("it reads what was written", func(t TL) {
+		Expect(t, t.fs.Create("a")).To(BeNil())
+
+		w, err := t.fs.Writer("a")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, w.Write([]byte("some-data-0"))).To(BeNil())
+		Expect(t, w.Write([]byte("some-data-1"))).To(BeNil())
+		w.Close()
+
+		r, err := t.fs.Reader("a", 0)
+		Expect(t, err == nil).To(BeTrue())
+		defer r.Close()
+
+		data, err := r.Read()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, data).To(Equal(reader.DataPacket{Payload: []byte("some-data-0"), Filename: "a", Index: 0}))
+
+		data, err = r.Read()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, data.Index).To(Equal(uint64(1)))
+
+		_, err = r.Read()
+		Expect(t, err).To(Equal(io.EOF))
+	})
+
+	o.Spec("it starts reading at the given index", func(t TL) {
+		Expect(t, t.fs.Create("a")).To(BeNil())
+
+		w, _ := t.fs.Writer("a")
+		w.Write([]byte("some-data-0"))
+		w.Write([]byte("some-data-1"))
+		w.Close()
+
+		r, err := t.fs.Reader("a", 1)
+		Expect(t, err == nil).To(BeTrue())
+		defer r.Close()
+
+		data, err := r.Read()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, data.Payload).To(Equal([]byte("some-data-1")))
+	})
+
+	o.Spec("it counts the complete records", func(t TL) {
+		Expect(t, t.fs.Create("a")).To(BeNil())
+
+		w, err := t.fs.Writer("a")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, w.Write([]byte("some-data-0"))).To(BeNil())
+
+		length, err := t.fs.Len("a")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, length).To(Equal(uint64(1)))
+
+		Expect(t, w.Write([]byte("some-data-1"))).To(BeNil())
+		length, err = t.fs.Len("a")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, length).To(Equal(uint64(2)))
+		w.Close()
+
+		// A partially written record is not counted.
+		f, err := os.OpenFile(filepath.Join(t.dir, "a"), os.O_APPEND|os.O_WRONLY, 0)
+		Expect(t, err == nil).To(BeTrue())
+		f.Write([]byte{0, 0, 0, 9, 's'})
+		f.Close()
+
+		length, err = t.fs.Len("a")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, length).To(Equal(uint64(2)))
+
+		_, err = t.fs.Len("b")
+		Expect(t, errors.Is(err, fs.ErrNotExist)).To(BeTrue())
+	})
+
+	o.Spec("it keeps a file open until every writer is closed", func(t TL) {
+		Expect(t, t.fs.Create("a")).To(BeNil())
+
+		a, err := t.fs.Writer("a")
+		Expect(t, err == nil).To(BeTrue())
+		b, err := t.fs.Writer("a")
+		Expect(t, err == nil).To(BeTrue())
+		defer b.Close()
+
+		a.Close()
+		a.Close()
+		Expect(t, b.Write([]byte("some-data"))).To(BeNil())
+
+		r, err := t.fs.Reader("a", 0)
+		Expect(t, err == nil).To(BeTrue())
+		defer r.Close()
+
+		data, err := r.Read()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, data.Payload).To(Equal([]byte("some-data")))
+	})
+
+	o.Spec("it does not write to a superseded range", func(t TL) {
+		old := router.JSONCodec{}.Encode(router.RangeName{High: 18446744073709551615, Term: 0})
+		Expect(t, t.fs.Create(old)).To(BeNil())
+
+		w, err := t.fs.Writer(old)
+		Expect(t, err == nil).To(BeTrue())
+		defer w.Close()
+		Expect(t, w.Write([]byte("some-data"))).To(BeNil())
+
+		other, err := localfs.New(t.dir)
+		Expect(t, err == nil).To(BeTrue())
+		ow, err := other.Writer(old)
+		Expect(t, err == nil).To(BeTrue())
+		defer ow.Close()
+
+		Expect(t, t.fs.Create(router.JSONCodec{}.Encode(router.RangeName{High: 18446744073709551615, Term: 1}))).To(BeNil())
+
+		Expect(t, errors.Is(w.Write([]byte("some-data")), localfs.ErrSuperseded)).To(BeTrue())
+		Expect(t, errors.Is(ow.Write([]byte("some-data")), localfs.ErrSuperseded)).To(BeTrue())
+	})
+
+	o.Spec("it only retires ranges in the same topic", func(t TL) {
+		old := router.PathCodec{}.Encode(router.RangeName{High: 18446744073709551615, Term: 0})
+		Expect(t, t.fs.Create(old)).To(BeNil())
+		Expect(t, t.fs.Create("some-topic/"+router.PathCodec{}.Encode(router.RangeName{High: 18446744073709551615, Term: 1}))).To(BeNil())
+
+		w, err := t.fs.Writer(old)
+		Expect(t, err == nil).To(BeTrue())
+		defer w.Close()
+		Expect(t, w.Write([]byte("some-data"))).To(BeNil())
+	})
+
+	o.Spec("it does not write to a file that was not created", func(t TL) {
+		_, err := t.fs.Writer("a")
+		Expect(t, err == nil).To(BeFalse())
+	})
+
+	o.Spec("it renames files", func(t TL) {
+		Expect(t, t.fs.Create("a")).To(BeNil())
+		Expect(t, t.fs.Rename("a", "some-topic/b")).To(BeNil())
+
+		files, _ := t.fs.List()
+		Expect(t, files).To(Equal([]string{"some-topic/b"}))
+	})
+	o.Spec("it rejects names that would escape the directory", func(t TL) {
+		Expect(t, t.fs.Create("a")).To(BeNil())
+
+		for _, name := range []string{"", ".", "../a", "/a", "some-topic/../../a", "some-topic//a", "a/", `..\a`} {
+			Expect(t, errors.Is(t.fs.Create(name), fs.ErrInvalid)).To(BeTrue())
+			Expect(t, errors.Is(t.fs.Rename("a", name), fs.ErrInvalid)).To(BeTrue())
+			Expect(t, errors.Is(t.fs.Rename(name, "b"), fs.ErrInvalid)).To(BeTrue())
+
+			_, err := t.fs.Writer(name)
+			Expect(t, errors.Is(err, fs.ErrInvalid)).To(BeTrue())
+
+			_, err = t.fs.Reader(name, 0)
+			Expect(t, errors.Is(err, fs.ErrInvalid)).To(BeTrue())
+		}
+
+		files, _ := t.fs.List()
+		Expect(t, files).To(Equal([]string{"a"}))
+	})
+}
@@ -1,19 +1,45 @@
 package maintainer
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"math"
 	"math/rand"
 	"sort"
 	"time"
 
+	"github.com/poy/petasos/metrics"
 	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
 )
 
 type RangeMetrics interface {
 	Metrics(file string) (metric router.Metric, err error)
 }
 
+// BulkRangeMetrics is implemented by RangeMetrics that can return the
+// metrics for every range file at once. When available, it is used instead
+// of calling Metrics for each file.
+type BulkRangeMetrics interface {
+	BulkMetrics() (metrics map[string]router.Metric, err error)
+}
+
+// PartialRangeMetrics is implemented by RangeMetrics that can succeed
+// without hearing from every router. missing lists the routers that did
+// not answer. The balancer acts on the metrics from the routers that did
+// answer and logs the missing ones.
+type PartialRangeMetrics interface {
+	PartialMetrics(file string) (metric router.Metric, missing []string, err error)
+}
+
+// PartialBulkRangeMetrics is the bulk version of PartialRangeMetrics.
+type PartialBulkRangeMetrics interface {
+	PartialBulkMetrics() (metrics map[string]router.Metric, missing []string, err error)
+}
+
 type FileSystem interface {
 	Create(file string) (err error)
 	List() (file []string, err error)
@@ -23,6 +49,10 @@ type Balancer struct {
 	rangeMetrics RangeMetrics
 	fs           FileSystem
 	conf         balancerConfig
+
+	tick     uint64
+	created  map[string]uint64
+	smoother *smoother
 }
 
 type balancerConfig struct {
@@ -30,67 +60,296 @@ type balancerConfig struct {
 	maxPerInterval uint64
 	minPerInterval uint64
 	min, max       uint64
+	maxActions     uint64
+	cooldown       uint64
+	smoothing      float64
+	hysteresis     uint64
+
+	balanceOn           BalanceOn
+	maxBytesPerInterval uint64
+	minBytesPerInterval uint64
+
+	maxLatency time.Duration
+
+	codec    router.RangeNameCodec
+	topic    router.Topic
+	observer Observer
+	tracer   router.Tracer
+	logger   *slog.Logger
 }
 
+// BalanceOn selects which metrics the balancer splits and combines ranges
+// on.
+type BalanceOn int
+
+const (
+	// BalanceOnWrites balances on the number of writes per interval.
+	BalanceOnWrites BalanceOn = iota
+
+	// BalanceOnBytes balances on the number of bytes written per interval.
+	BalanceOnBytes
+
+	// BalanceOnWritesAndBytes splits a range when either its writes or its
+	// bytes are too high, and only combines ranges when both are low.
+	BalanceOnWritesAndBytes
+)
+
 type BalancerOpts func(c *balancerConfig)
 
+// StartBalancer starts balancing in the background. It panics if the
+// config is invalid; use NewBalancer to get an error instead.
 func StartBalancer(rangeMetrics RangeMetrics, fs FileSystem, opts ...BalancerOpts) {
+	b, err := NewBalancer(rangeMetrics, fs, opts...)
+	if err != nil {
+		log.Panicf("Invalid config: %s", err)
+	}
+
+	b.Start()
+}
+
+// NewBalancer returns a Balancer, or an error if the config is invalid. It
+// does nothing until started.
+func NewBalancer(rangeMetrics RangeMetrics, fs FileSystem, opts ...BalancerOpts) (*Balancer, error) {
 	conf := balancerConfig{
 		interval:       5 * time.Second,
 		maxPerInterval: 2500,
 		minPerInterval: 20,
 		max:            100,
 		min:            3,
+		maxActions:     1,
+		cooldown:       3,
+		smoothing:      1,
+		hysteresis:     2,
+
+		balanceOn:           BalanceOnWrites,
+		maxBytesPerInterval: 64 * 1024 * 1024,
+		minBytesPerInterval: 512 * 1024,
+
+		codec:    router.JSONCodec{},
+		observer: nopObserver{},
+		tracer:   router.NopTracer{},
+		logger:   slog.Default(),
 	}
 
 	for _, opt := range opts {
 		opt(&conf)
 	}
 
-	if conf.min > conf.max || conf.min == 0 || conf.max == 0 {
-		log.Panicf("Invalid config: %+v", conf)
+	conf.codec = conf.topic.Codec(conf.codec)
+
+	if conf.min > conf.max || conf.min == 0 || conf.max == 0 || conf.maxActions == 0 {
+		return nil, fmt.Errorf("invalid config: %+v", conf)
 	}
 
-	b := &Balancer{
+	if err := conf.validateThresholds(); err != nil {
+		return nil, err
+	}
+
+	if conf.smoothing <= 0 || conf.smoothing > 1 || conf.hysteresis == 0 {
+		return nil, fmt.Errorf("invalid config: %+v", conf)
+	}
+
+	return &Balancer{
 		rangeMetrics: rangeMetrics,
 		fs:           fs,
 		conf:         conf,
-	}
+		created:      make(map[string]uint64),
+		smoother:     newSmoother(conf.smoothing),
+	}, nil
+}
 
+// Start starts balancing in the background.
+func (b *Balancer) Start() {
 	go b.run()
 }
 
+// validateThresholds ensures that combining two ranges below the min
+// threshold does not produce a range that is immediately split again. Only
+// the thresholds used by balanceOn are checked.
+func (c balancerConfig) validateThresholds() error {
+	writes := c.balanceOn == BalanceOnWrites || c.balanceOn == BalanceOnWritesAndBytes
+	if writes && 2*c.minPerInterval > c.maxPerInterval {
+		return fmt.Errorf("invalid config (write thresholds overlap): min %d, max %d", c.minPerInterval, c.maxPerInterval)
+	}
+
+	bytes := c.balanceOn == BalanceOnBytes || c.balanceOn == BalanceOnWritesAndBytes
+	if bytes && 2*c.minBytesPerInterval > c.maxBytesPerInterval {
+		return fmt.Errorf("invalid config (byte thresholds overlap): min %d, max %d", c.minBytesPerInterval, c.maxBytesPerInterval)
+	}
+
+	return nil
+}
+
 func (b *Balancer) run() {
 	for range time.Tick(b.conf.interval) {
-		ranges, actual, lastTerm, ok := validRanges(b.fs, b.rangeMetrics)
+		b.tick++
+		b.expireCooldowns()
+
+		list, ok := validRanges(b.fs, b.rangeMetrics, b.conf.codec, b.conf.logger)
 		if !ok {
 			continue
 		}
 
-		if uint64(len(actual)) < b.conf.min {
+		if uint64(len(list.actual)) < b.conf.min {
 			b.seedRanges()
 			continue
 		}
 
-		sort.Sort(rangeInfos(ranges))
+		if len(list.missing) > 0 {
+			b.conf.logger.Warn("balancing on partial metrics", "missing", list.missing)
+		}
+
+		ranges := list.ranges
+		b.smoother.smooth(ranges, b.isHot, b.isCold)
+		sort.Slice(ranges, func(i, j int) bool {
+			return b.load(ranges[i]) < b.load(ranges[j])
+		})
+
+		b.balance(ranges, list.snapshot)
+	}
+}
+
+// balance performs up to maxActions splits and combines. Failed actions do
+// not count towards maxActions. Each range takes part in at most one action
+// per interval, and ranges the balancer created recently are left alone
+// until their cool-down expires.
+func (b *Balancer) balance(ranges []rangeInfo, snapshot *topology.Snapshot) {
+	count := uint64(len(ranges))
+	lastTerm := snapshot.LastTerm()
+	used := make(map[string]bool)
+
+	for actions := uint64(0); actions < b.conf.maxActions; {
+		available := b.available(ranges, used)
+		if len(available) == 0 {
+			return
+		}
+
+		last := available[len(available)-1]
+		if b.smoother.hot(last.file) >= b.conf.hysteresis && count < b.conf.max {
+			if b.splitRange(last, snapshot, lastTerm) {
+				lastTerm += 2
+				count++
+				actions++
+			}
+			used[last.file] = true
+			continue
+		}
+
+		if count > b.conf.min {
+			x, y, ok := coldestAdjacent(available, b.load, b.combinable)
+			if !ok {
+				return
+			}
 
-		last := ranges[len(ranges)-1]
-		if last.writeCount > b.conf.maxPerInterval && uint64(len(ranges)) < b.conf.max {
-			b.splitRange(last, lastTerm)
+			if b.combineRange(x, y, snapshot, lastTerm) {
+				lastTerm++
+				count--
+				actions++
+			}
+			used[x.file] = true
+			used[y.file] = true
 			continue
 		}
 
-		first := ranges[0]
-		if first.writeCount < b.conf.minPerInterval && uint64(len(ranges)) > b.conf.min {
-			b.combineRange(first, ranges[1], lastTerm)
+		return
+	}
+}
+
+// load returns how busy a range is relative to the split threshold(s).
+func (b *Balancer) load(r rangeInfo) float64 {
+	writes := ratio(r.writeCount, b.conf.maxPerInterval)
+	bytes := ratio(r.byteCount, b.conf.maxBytesPerInterval)
+
+	switch b.conf.balanceOn {
+	case BalanceOnBytes:
+		return bytes
+	case BalanceOnWritesAndBytes:
+		return math.Max(writes, bytes)
+	default:
+		return writes
+	}
+}
+
+func (b *Balancer) isHot(r rangeInfo) bool {
+	writes := r.writeCount > b.conf.maxPerInterval
+	bytes := r.byteCount > b.conf.maxBytesPerInterval
+
+	switch b.conf.balanceOn {
+	case BalanceOnBytes:
+		return bytes
+	case BalanceOnWritesAndBytes:
+		return writes || bytes
+	default:
+		return writes
+	}
+}
+
+func (b *Balancer) isCold(r rangeInfo) bool {
+	writes := r.writeCount < b.conf.minPerInterval
+	bytes := r.byteCount < b.conf.minBytesPerInterval
+
+	switch b.conf.balanceOn {
+	case BalanceOnBytes:
+		return bytes
+	case BalanceOnWritesAndBytes:
+		return writes && bytes
+	default:
+		return writes
+	}
+}
+
+func ratio(value, max uint64) float64 {
+	if max == 0 {
+		return float64(value)
+	}
+	return float64(value) / float64(max)
+}
+
+// available returns the sorted ranges that have not been used yet this
+// interval, are not cooling down and are not degraded.
+func (b *Balancer) available(ranges []rangeInfo, used map[string]bool) (result []rangeInfo) {
+	for _, r := range ranges {
+		if used[r.file] || b.coolingDown(r.file) || b.degraded(r) {
 			continue
 		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// degraded reports whether the range's backend is too slow to be split or
+// combined.
+func (b *Balancer) degraded(r rangeInfo) bool {
+	return b.conf.maxLatency > 0 && r.latency > b.conf.maxLatency
+}
+
+func (b *Balancer) coolingDown(file string) bool {
+	tick, ok := b.created[file]
+	return ok && b.tick-tick < b.conf.cooldown
+}
+
+func (b *Balancer) expireCooldowns() {
+	for file, tick := range b.created {
+		if b.tick-tick >= b.conf.cooldown {
+			delete(b.created, file)
+		}
+	}
+}
+
+func (b *Balancer) create(file string) error {
+	if err := b.fs.Create(file); err != nil {
+		return err
+	}
+
+	if b.conf.cooldown > 0 {
+		b.created[file] = b.tick
 	}
+	return nil
 }
 
 func (b *Balancer) seedRanges() {
-	log.Print("Seeding ranges...")
-	defer log.Print("Done seeding ranges.")
+	b.conf.logger.Info("seeding ranges", "count", b.conf.min)
+	defer b.conf.logger.Info("done seeding ranges")
 
 	width := 18446744073709551615 / b.conf.min
 
@@ -110,166 +369,291 @@ func (b *Balancer) seedRanges() {
 			newRange.High = 18446744073709551615
 		}
 
-		rangeName, _ := json.Marshal(newRange)
+		rangeName := b.conf.codec.Encode(newRange)
 
-		if err := b.fs.Create(string(rangeName)); err != nil {
-			log.Printf("Error creating file %s: %s", string(rangeName), err)
+		_, span := b.conf.tracer.Start(context.Background(), "petasos.balancer.Seed")
+		span.SetRange(newRange)
+
+		if err := b.fs.Create(rangeName); err != nil {
+			b.conf.logger.Error("failed to create range", "range", newRange, "file", rangeName, "err", err)
+			span.RecordError(err)
+			span.End()
+			b.conf.observer.Observe(ActionFailed{Action: ActionSeed, Range: newRange, Err: err})
+			continue
 		}
+		span.End()
+
+		b.conf.observer.Observe(RangeSeeded{Range: newRange})
 	}
 }
 
-func (b *Balancer) combineRange(first, next rangeInfo, lastTerm uint64) {
-	log.Printf("Combining %s and %s...", first.file, next.file)
-	defer log.Printf("Done combining %s and %s.", first.file, next.file)
-
-	min := first.hashRange.Low
-	if min > next.hashRange.Low {
-		min = next.hashRange.Low
+func (b *Balancer) combineRange(first, next rangeInfo, snapshot *topology.Snapshot, lastTerm uint64) bool {
+	combined, err := CombineRanges(first.hashRange, next.hashRange, lastTerm)
+	if err != nil {
+		b.conf.logger.Warn("refusing to combine non-adjacent ranges", "first", first.hashRange, "next", next.hashRange)
+		return false
 	}
 
-	max := first.hashRange.High
-	if max < next.hashRange.High {
-		max = next.hashRange.High
-	}
+	b.conf.logger.Info("combining ranges", "first", first.hashRange, "next", next.hashRange)
+	defer b.conf.logger.Info("done combining ranges", "first", first.hashRange, "next", next.hashRange)
 
-	combined := router.RangeName{
-		Term: lastTerm + 1,
-		Low:  min,
-		High: max,
-		Rand: rand.Int63(),
+	if err := ValidateChange(combined, snapshot, first.file, next.file); err != nil {
+		b.conf.logger.Warn("refusing to combine ranges", "first", first.hashRange, "next", next.hashRange, "err", err)
+		return false
 	}
 
-	combinedName, _ := json.Marshal(combined)
+	combinedName := b.conf.codec.Encode(combined)
+
+	_, span := b.conf.tracer.Start(context.Background(), "petasos.balancer.Combine")
+	defer span.End()
+	span.SetRange(combined)
 
-	if err := b.fs.Create(string(combinedName)); err != nil {
-		log.Printf("Error creating file %s: %s", string(combinedName), err)
+	if err := b.create(combinedName); err != nil {
+		b.conf.logger.Error("failed to create range", "range", combined, "file", combinedName, "err", err)
+		span.RecordError(err)
+		b.conf.observer.Observe(ActionFailed{Action: ActionCombine, Range: combined, Err: err})
+		return false
 	}
-}
 
-func (b *Balancer) splitRange(last rangeInfo, lastTerm uint64) {
-	log.Printf("Splitting %s...", last.file)
-	defer log.Printf("Done splitting %s.", last.file)
+	b.conf.observer.Observe(RangesCombined{
+		First:    first.hashRange,
+		Next:     next.hashRange,
+		Combined: combined,
+	})
 
-	middle := (last.hashRange.High-last.hashRange.Low)/2 + last.hashRange.Low
-	low := router.RangeName{
-		Term: lastTerm + 1,
-		Low:  last.hashRange.Low,
-		High: middle,
-		Rand: rand.Int63(),
-	}
+	return true
+}
 
-	high := router.RangeName{
-		Term: lastTerm + 2,
-		Low:  middle + 1,
-		High: last.hashRange.High,
-		Rand: rand.Int63(),
+func (b *Balancer) splitRange(last rangeInfo, snapshot *topology.Snapshot, lastTerm uint64) bool {
+	if err := ValidateChange(last.hashRange, snapshot, last.file); err != nil {
+		b.conf.logger.Warn("refusing to split range", "range", last.hashRange, "err", err)
+		return false
 	}
 
-	lowName, _ := json.Marshal(low)
-	highName, _ := json.Marshal(high)
+	b.conf.logger.Info("splitting range", "range", last.hashRange)
+	defer b.conf.logger.Info("done splitting range", "range", last.hashRange)
+
+	low, high := SplitRange(last.hashRange, lastTerm)
+
+	lowName := b.conf.codec.Encode(low)
+	highName := b.conf.codec.Encode(high)
 
-	if err := b.fs.Create(string(lowName)); err != nil {
-		log.Printf("Error creating file %s to read only: %s", string(lowName), err)
+	_, span := b.conf.tracer.Start(context.Background(), "petasos.balancer.Split")
+	defer span.End()
+	span.SetRange(last.hashRange)
+
+	lowErr := b.create(lowName)
+	if lowErr != nil {
+		b.conf.logger.Error("failed to create range", "range", low, "file", lowName, "err", lowErr)
+		span.RecordError(lowErr)
+		b.conf.observer.Observe(ActionFailed{Action: ActionSplit, Range: low, Err: lowErr})
+	}
+
+	highErr := b.create(highName)
+	if highErr != nil {
+		b.conf.logger.Error("failed to create range", "range", high, "file", highName, "err", highErr)
+		span.RecordError(highErr)
+		b.conf.observer.Observe(ActionFailed{Action: ActionSplit, Range: high, Err: highErr})
 	}
 
-	if err := b.fs.Create(string(highName)); err != nil {
-		log.Printf("Error creating file %s to read only: %s", string(highName), err)
+	if lowErr != nil || highErr != nil {
+		return false
 	}
+
+	b.conf.observer.Observe(RangeSplit{Parent: last.hashRange, Low: low, High: high})
+	return true
 }
 
-func validRanges(fs FileSystem, rangeMetrics RangeMetrics) (ranges, actual []rangeInfo, lastTerm uint64, ok bool) {
-	list, err := fs.List()
+// rangeList is the ranges listed at the start of an interval.
+type rangeList struct {
+	// ranges are the ranges with metrics that still own every hash they
+	// cover.
+	ranges []rangeInfo
+
+	// actual is every range with metrics.
+	actual []rangeInfo
+
+	// snapshot is every listed range, including those without metrics.
+	snapshot *topology.Snapshot
+
+	// missing lists the routers that did not report metrics.
+	missing []string
+}
+
+func validRanges(fs FileSystem, rangeMetrics RangeMetrics, codec router.RangeNameCodec, logger *slog.Logger) (list rangeList, ok bool) {
+	files, err := fs.List()
 	if err != nil {
-		log.Printf("Failed to list files: %s", err)
-		return nil, nil, 0, false
+		logger.Error("failed to list files", "err", err)
+		return rangeList{}, false
 	}
 
-	for _, file := range list {
-		var rn router.RangeName
-		if err = json.Unmarshal([]byte(file), &rn); err != nil {
-			log.Printf("Unable to unmarshal file name %s: %s", file, err)
-			continue
-		}
+	list.snapshot = topology.New(files, codec)
 
-		if lastTerm < rn.Term {
-			lastTerm = rn.Term
-		}
+	bulk, missing := bulkMetrics(rangeMetrics, logger)
+	list.missing = missing
+
+	for _, file := range list.snapshot.Invalid() {
+		logger.Warn("unable to decode file name", "file", file)
+	}
+
+	for _, r := range list.snapshot.Ranges() {
+		file, rn := r.File, r.Name
 
-		metric, err := rangeMetrics.Metrics(file)
+		metric, missing, err := fileMetrics(rangeMetrics, bulk, file)
 		if err != nil {
-			log.Printf("Failed to fetch metrics for %s: %s", file, err)
+			logger.Error("failed to fetch metrics", "range", rn, "file", file, "err", err)
 			continue
 		}
 
+		if len(list.missing) == 0 {
+			list.missing = missing
+		}
+
 		if metric.ErrCount >= 5 {
 			continue
 		}
 
-		ranges = append(ranges, rangeInfo{
+		list.actual = append(list.actual, rangeInfo{
 			file:       file,
 			writeCount: metric.WriteCount,
+			byteCount:  metric.ByteCount,
+			latency:    metric.Latency.P99(),
 			hashRange:  rn,
 		})
 	}
 
-	valid := removeOverlaps(ranges)
+	list.ranges = owning(list.actual, list.snapshot)
 
-	return valid, ranges, lastTerm, true
+	return list, true
 }
 
-func removeOverlaps(ranges []rangeInfo) (result []rangeInfo) {
-	for i, x := range ranges {
-		for j, y := range ranges {
-			if i == j || !overlap(x.hashRange, y.hashRange) {
+// owning leaves out the ranges that newer ones replace: those that no
+// longer own any hash and the older side of every overlap. Ranges that only
+// share a bound (one's High is the other's Low) do not overlap.
+func owning(ranges []rangeInfo, snapshot *topology.Snapshot) (result []rangeInfo) {
+	replaced := make(map[string]bool)
+	for _, r := range snapshot.Superseded() {
+		replaced[r.File] = true
+	}
+	for _, o := range snapshot.Overlaps() {
+		if !sharedBound(o) {
+			replaced[o.Older.File] = true
+		}
+	}
+
+	for _, r := range ranges {
+		if !replaced[r.file] {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// sharedBound reports whether the overlap is only the hash where one range
+// ends and the other starts.
+func sharedBound(o topology.Overlap) bool {
+	if o.Low != o.High {
+		return false
+	}
+
+	older, newer := o.Older.Name, o.Newer.Name
+	return (older.High == o.Low && newer.Low == o.Low) || (newer.High == o.Low && older.Low == o.Low)
+}
+
+// bulkMetrics fetches every range's metrics at once if rangeMetrics
+// supports it. It returns nil if it does not or the call fails. A
+// metrics.ErrBulkUnsupported error is not logged as the caller falls back
+// to fetching each file's metrics.
+func bulkMetrics(rangeMetrics RangeMetrics, logger *slog.Logger) (all map[string]router.Metric, missing []string) {
+	var err error
+	switch bulk := rangeMetrics.(type) {
+	case PartialBulkRangeMetrics:
+		all, missing, err = bulk.PartialBulkMetrics()
+	case BulkRangeMetrics:
+		all, err = bulk.BulkMetrics()
+	default:
+		return nil, nil
+	}
+
+	if errors.Is(err, metrics.ErrBulkUnsupported) {
+		return nil, nil
+	}
+
+	if err != nil {
+		logger.Error("failed to fetch bulk metrics", "err", err)
+		return nil, nil
+	}
+
+	return all, missing
+}
+
+func fileMetrics(rangeMetrics RangeMetrics, bulk map[string]router.Metric, file string) (metric router.Metric, missing []string, err error) {
+	if bulk != nil {
+		return bulk[file], nil, nil
+	}
+
+	if partial, ok := rangeMetrics.(PartialRangeMetrics); ok {
+		return partial.PartialMetrics(file)
+	}
+
+	metric, err = rangeMetrics.Metrics(file)
+	return metric, nil, err
+}
+
+// combinable reports whether x and y may be combined: one of them has been
+// cold for the hysteresis and the combined range would not be split again.
+func (b *Balancer) combinable(x, y rangeInfo) bool {
+	cold := b.smoother.cold(x.file) >= b.conf.hysteresis || b.smoother.cold(y.file) >= b.conf.hysteresis
+	if !cold {
+		return false
+	}
+
+	return !b.isHot(rangeInfo{
+		writeCount: x.writeCount + y.writeCount,
+		byteCount:  x.byteCount + y.byteCount,
+	})
+}
+
+// coldestAdjacent returns the pair of neighboring ranges accepted by allow
+// with the lowest combined load. The given ranges must not overlap.
+func coldestAdjacent(ranges []rangeInfo, load func(rangeInfo) float64, allow func(x, y rangeInfo) bool) (x, y rangeInfo, ok bool) {
+	var coldest float64
+	for _, a := range ranges {
+		for _, b := range ranges {
+			if !adjacent(a.hashRange, b.hashRange) || !allow(a, b) {
 				continue
 			}
 
-			removeIdx := i
-			if x.hashRange.Term > y.hashRange.Term {
-				removeIdx = j
+			sum := load(a) + load(b)
+			if ok && sum >= coldest {
+				continue
 			}
 
-			return removeOverlaps(append(ranges[:removeIdx], ranges[removeIdx+1:]...))
+			x, y, coldest, ok = a, b, sum, true
 		}
 	}
 
-	return ranges
+	return x, y, ok
 }
 
-func overlap(x, y router.RangeName) bool {
-	return (x.Low >= y.Low && x.Low < y.High) || (y.Low >= x.Low && y.Low < x.High)
+// adjacent reports whether y starts immediately after x ends.
+func adjacent(x, y router.RangeName) bool {
+	return x.High != 18446744073709551615 && x.High+1 == y.Low
 }
 
 type rangeInfo struct {
 	file       string
 	writeCount uint64
+	byteCount  uint64
+	latency    time.Duration
 	hashRange  router.RangeName
 }
 
-type rangeInfos []rangeInfo
-
-func (r rangeInfos) Len() int {
-	return len(r)
-}
-
-func (r rangeInfos) Less(i, j int) bool {
-	return r[i].writeCount < r[j].writeCount
-}
-
-func (r rangeInfos) Swap(i, j int) {
-	tmp := r[i]
-	r[i] = r[j]
-	r[j] = tmp
-}
-
-func buildRangeName(low, high, term uint64) string {
-	rn := router.RangeName{
+func buildRangeName(codec router.RangeNameCodec, low, high, term uint64) string {
+	return codec.Encode(router.RangeName{
 		Low:  low,
 		High: high,
 		Term: term,
 		Rand: rand.Int63(),
-	}
-
-	j, _ := json.Marshal(rn)
-	return string(j)
+	})
 }
@@ -3,12 +3,15 @@
 package maintainer_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"log/slog"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -17,6 +20,7 @@ import (
 	. "github.com/poy/onpar/expect"
 	. "github.com/poy/onpar/matchers"
 	"github.com/poy/petasos/maintainer"
+	"github.com/poy/petasos/metrics"
 	"github.com/poy/petasos/router"
 )
 
@@ -130,6 +134,715 @@ func TestBalancer(t *testing.T) {
 	})
 }
 
+func TestBalancerCombinesAdjacent(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+		maintainer.StartBalancer(mockRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+		)
+
+		files := []string{
+			buildRangeName(0, 6148914691236517205, 0),
+			buildRangeName(6148914691236517206, 12297829382473034410, 1),
+			buildRangeName(12297829382473034411, 18446744073709551615, 2),
+		}
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, files)
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		tb := TB{
+			T:                t,
+			files:            files,
+			repeatedFiles:    make(chan string, 100),
+			mockFileSystem:   mockFileSystem,
+			mockRangeMetrics: mockRangeMetrics,
+		}
+
+		go serviceMetrics(tb, tb.repeatedFiles, map[string]uint64{
+			files[0]: 1,
+			files[1]: 100,
+			files[2]: 2,
+		})
+
+		return tb
+	})
+
+	o.Spec("it combines the coldest adjacent ranges", func(t TB) {
+		files := stripRand(toSlice(t.mockFileSystem.CreateInput.File, 1))
+		Expect(t, files).To(Contain(
+			buildRangeName(0, 12297829382473034410, 3),
+		))
+	})
+}
+
+func TestBalancerCombineStaysCool(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+		maintainer.StartBalancer(mockRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+		)
+
+		files := []string{
+			buildRangeName(0, 6148914691236517205, 0),
+			buildRangeName(6148914691236517206, 12297829382473034410, 1),
+			buildRangeName(12297829382473034411, 18446744073709551615, 2),
+		}
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, files)
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		tb := TB{
+			T:                t,
+			files:            files,
+			repeatedFiles:    make(chan string, 100),
+			mockFileSystem:   mockFileSystem,
+			mockRangeMetrics: mockRangeMetrics,
+		}
+
+		go serviceMetrics(tb, tb.repeatedFiles, map[string]uint64{
+			files[0]: 1,
+			files[1]: 2500,
+			files[2]: 2400,
+		})
+
+		return tb
+	})
+
+	o.Spec("it does not combine ranges that would be split again", func(t TB) {
+		Expect(t, t.mockFileSystem.CreateCalled).To(Always(HaveLen(0)))
+	})
+}
+
+func TestBalancerMultipleActions(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+		maintainer.StartBalancer(mockRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+			maintainer.WithMaxActionsPerInterval(2),
+		)
+
+		files := []string{
+			buildRangeName(0, 9223372036854775807, 0),
+			buildRangeName(9223372036854775808, 18446744073709551615, 1),
+		}
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, files)
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		tb := TB{
+			T:                t,
+			files:            files,
+			repeatedFiles:    make(chan string, 100),
+			mockFileSystem:   mockFileSystem,
+			mockRangeMetrics: mockRangeMetrics,
+		}
+
+		go serviceMetrics(tb, tb.repeatedFiles, map[string]uint64{
+			files[0]: 2600,
+			files[1]: 2700,
+		})
+
+		return tb
+	})
+
+	o.Spec("it splits each hot range in the same interval", func(t TB) {
+		files := stripRand(toSlice(t.mockFileSystem.CreateInput.File, 4))
+		Expect(t, files).To(Contain(
+			buildRangeName(9223372036854775808, 13835058055282163711, 2),
+			buildRangeName(13835058055282163712, 18446744073709551615, 3),
+			buildRangeName(0, 4611686018427387903, 4),
+			buildRangeName(4611686018427387904, 9223372036854775807, 5),
+		))
+	})
+}
+
+func TestBalancerCooldown(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it does not split a range it created until the cool-down passes", func(t *testing.T) {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+
+		files := []string{
+			buildRangeName(0, 9223372036854775807, 0),
+			buildRangeName(9223372036854775808, 18446744073709551615, 1),
+		}
+		created := serviceFileSystem(mockFileSystem, files)
+
+		go func() {
+			for file := range mockRangeMetrics.MetricsInput.File {
+				writes := uint64(2600)
+				if file == files[0] {
+					writes = 25
+				}
+				mockRangeMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: writes}
+				mockRangeMetrics.MetricsOutput.Err <- nil
+			}
+		}()
+
+		maintainer.StartBalancer(mockRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+			maintainer.WithCooldownIntervals(10),
+		)
+
+		split := receiveCreate(t, created)
+		Expect(t, receiveCreate(t, created).tick).To(Equal(split.tick))
+
+		next := receiveCreate(t, created)
+		Expect(t, next.tick-split.tick >= 10).To(BeTrue())
+	})
+
+	o.Spec("it does not combine the halves of a split range straight away by default", func(t *testing.T) {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+
+		files := []string{
+			buildRangeName(0, 9223372036854775807, 0),
+			buildRangeName(9223372036854775808, 18446744073709551615, 1),
+		}
+		created := serviceFileSystem(mockFileSystem, files)
+
+		// The halves have no writes, so they are cold from the start.
+		go serviceMetricSequences(mockRangeMetrics, map[string][]uint64{
+			files[0]: {100},
+			files[1]: {2600},
+		})
+
+		maintainer.StartBalancer(mockRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+		)
+
+		split := receiveCreate(t, created)
+		Expect(t, receiveCreate(t, created).tick).To(Equal(split.tick))
+
+		combined := receiveCreate(t, created)
+		Expect(t, combined.tick-split.tick >= 3).To(BeTrue())
+	})
+}
+
+func TestBalancerHysteresis(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		return TB{
+			T: t,
+			files: []string{
+				buildRangeName(0, 9223372036854775807, 0),
+				buildRangeName(9223372036854775808, 18446744073709551615, 1),
+			},
+			mockFileSystem:   newMockFileSystem(),
+			mockRangeMetrics: newMockRangeMetrics(),
+		}
+	})
+
+	o.Spec("it waits for the range to stay hot before splitting", func(t TB) {
+		created := serviceFileSystem(t.mockFileSystem, t.files)
+		go serviceMetricSequences(t.mockRangeMetrics, map[string][]uint64{
+			t.files[0]: {100},
+			t.files[1]: {2600},
+		})
+
+		maintainer.StartBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+			maintainer.WithHysteresisIntervals(5),
+		)
+
+		Expect(t, receiveCreate(t.T, created).tick).To(Equal(5))
+	})
+
+	o.Spec("it does not split a range that is hot for a single interval", func(t TB) {
+		serviceFileSystem(t.mockFileSystem, t.files)
+		repeated := make(chan string, 100)
+		go serviceMetricSequences(t.mockRangeMetrics, map[string][]uint64{
+			t.files[0]: {100},
+			t.files[1]: {100, 100, 5000, 100},
+		}, repeated)
+
+		maintainer.StartBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+			maintainer.WithHysteresisIntervals(2),
+		)
+
+		toSlice(repeated, 12)
+		Expect(t, t.mockFileSystem.CreateCalled).To(Always(HaveLen(0)))
+	})
+
+	o.Spec("it does not act on ranges between the thresholds", func(t TB) {
+		serviceFileSystem(t.mockFileSystem, t.files)
+		repeated := make(chan string, 100)
+		go serviceMetricSequences(t.mockRangeMetrics, map[string][]uint64{
+			t.files[0]: {100},
+			t.files[1]: {2400},
+		}, repeated)
+
+		maintainer.StartBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+		)
+
+		toSlice(repeated, 12)
+		Expect(t, t.mockFileSystem.CreateCalled).To(Always(HaveLen(0)))
+	})
+}
+
+func TestBalancerSmoothing(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		return TB{
+			T: t,
+			files: []string{
+				buildRangeName(0, 9223372036854775807, 0),
+				buildRangeName(9223372036854775808, 18446744073709551615, 1),
+			},
+			mockFileSystem:   newMockFileSystem(),
+			mockRangeMetrics: newMockRangeMetrics(),
+		}
+	})
+
+	o.Spec("it does not split a range after a single burst", func(t TB) {
+		serviceFileSystem(t.mockFileSystem, t.files)
+		repeated := make(chan string, 100)
+		go serviceMetricSequences(t.mockRangeMetrics, map[string][]uint64{
+			t.files[0]: {100},
+			t.files[1]: {100, 100, 100, 5000, 100},
+		}, repeated)
+
+		maintainer.StartBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+			maintainer.WithSmoothing(0.2),
+		)
+
+		toSlice(repeated, 12)
+		Expect(t, t.mockFileSystem.CreateCalled).To(Always(HaveLen(0)))
+	})
+
+	o.Spec("it splits a range under sustained load", func(t TB) {
+		created := serviceFileSystem(t.mockFileSystem, t.files)
+		go serviceMetricSequences(t.mockRangeMetrics, map[string][]uint64{
+			t.files[0]: {100},
+			t.files[1]: {100, 5000},
+		})
+
+		maintainer.StartBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+			maintainer.WithSmoothing(0.2),
+			maintainer.WithHysteresisIntervals(1),
+		)
+
+		// The average only passes 2500 on the fourth interval of 5000
+		// writes: 100, 1080, 1864, 2491, 2993.
+		Expect(t, receiveCreate(t.T, created).tick).To(Equal(5))
+	})
+}
+
+func TestBalancerThresholds(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		return TB{
+			T:                t,
+			mockFileSystem:   newMockFileSystem(),
+			mockRangeMetrics: newMockRangeMetrics(),
+		}
+	})
+
+	o.Spec("it returns an error when the write thresholds overlap", func(t TB) {
+		_, err := maintainer.NewBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithMinWritesPerInterval(100),
+			maintainer.WithMaxWritesPerInterval(150),
+		)
+		Expect(t, err).To(Not(BeNil()))
+	})
+
+	o.Spec("it returns an error when the byte thresholds overlap", func(t TB) {
+		_, err := maintainer.NewBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalanceOn(maintainer.BalanceOnWritesAndBytes),
+			maintainer.WithMinBytesPerInterval(100),
+			maintainer.WithMaxBytesPerInterval(150),
+		)
+		Expect(t, err).To(Not(BeNil()))
+	})
+
+	o.Spec("it ignores the byte thresholds when balancing on writes", func(t TB) {
+		_, err := maintainer.NewBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithMinBytesPerInterval(100),
+			maintainer.WithMaxBytesPerInterval(150),
+		)
+		Expect(t, err).To(BeNil())
+	})
+
+	o.Spec("it panics when started with an invalid config", func(t TB) {
+		defer func() {
+			Expect(t, recover()).To(Not(BeNil()))
+		}()
+
+		maintainer.StartBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithMinWritesPerInterval(100),
+			maintainer.WithMaxWritesPerInterval(150),
+		)
+	})
+
+	o.Spec("it ignores the write thresholds when balancing on bytes", func(t TB) {
+		_, err := maintainer.NewBalancer(t.mockRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalanceOn(maintainer.BalanceOnBytes),
+			maintainer.WithMinWritesPerInterval(100),
+			maintainer.WithMaxWritesPerInterval(150),
+		)
+		Expect(t, err).To(BeNil())
+	})
+}
+
+func TestBalancerBytes(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+		maintainer.StartBalancer(mockRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(2),
+			maintainer.WithBalanceOn(maintainer.BalanceOnBytes),
+			maintainer.WithMaxBytesPerInterval(1000),
+			maintainer.WithMinBytesPerInterval(10),
+		)
+
+		files := []string{
+			buildRangeName(0, 9223372036854775807, 0),
+			buildRangeName(9223372036854775808, 18446744073709551615, 1),
+		}
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, files)
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		tb := TB{
+			T:                t,
+			files:            files,
+			repeatedFiles:    make(chan string, 100),
+			mockFileSystem:   mockFileSystem,
+			mockRangeMetrics: mockRangeMetrics,
+		}
+
+		go serviceFullMetrics(tb, tb.repeatedFiles, map[string]router.Metric{
+			files[0]: {WriteCount: 2600, ByteCount: 100},
+			files[1]: {WriteCount: 1, ByteCount: 2000},
+		})
+
+		return tb
+	})
+
+	o.Spec("it splits the range with the most bytes", func(t TB) {
+		files := stripRand(toSlice(t.mockFileSystem.CreateInput.File, 2))
+		Expect(t, files).To(Contain(
+			buildRangeName(9223372036854775808, 13835058055282163711, 2),
+			buildRangeName(13835058055282163712, 18446744073709551615, 3),
+		))
+	})
+}
+
+func TestBalancerDegraded(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+		maintainer.StartBalancer(mockRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(2),
+			maintainer.WithMaxLatency(time.Second),
+		)
+
+		files := []string{
+			buildRangeName(0, 9223372036854775807, 0),
+			buildRangeName(9223372036854775808, 18446744073709551615, 1),
+		}
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, files)
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		tb := TB{
+			T:                t,
+			files:            files,
+			repeatedFiles:    make(chan string, 100),
+			mockFileSystem:   mockFileSystem,
+			mockRangeMetrics: mockRangeMetrics,
+		}
+
+		var slow router.Histogram
+		slow.Observe(5 * time.Second)
+
+		go serviceFullMetrics(tb, tb.repeatedFiles, map[string]router.Metric{
+			files[0]: {WriteCount: 2600},
+			files[1]: {WriteCount: 5000, Latency: slow},
+		})
+
+		return tb
+	})
+
+	o.Spec("it does not split the degraded range", func(t TB) {
+		files := stripRand(toSlice(t.mockFileSystem.CreateInput.File, 2))
+		Expect(t, files).To(Contain(
+			buildRangeName(0, 4611686018427387903, 2),
+			buildRangeName(4611686018427387904, 9223372036854775807, 3),
+		))
+	})
+}
+
+func TestBalancerBulkMetrics(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		mockFileSystem := newMockFileSystem()
+		mockBulkRangeMetrics := newMockBulkRangeMetrics()
+		maintainer.StartBalancer(mockBulkRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+		)
+
+		files := []string{
+			buildRangeName(0, 9223372036854775807, 0),
+			buildRangeName(9223372036854775808, 18446744073709551615, 1),
+		}
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, files)
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		testhelpers.AlwaysReturn(mockBulkRangeMetrics.BulkMetricsOutput.Metrics, map[string]router.Metric{
+			files[0]: {WriteCount: 25},
+			files[1]: {WriteCount: 2600},
+		})
+		close(mockBulkRangeMetrics.BulkMetricsOutput.Err)
+
+		return TB{
+			T:                t,
+			files:            files,
+			mockFileSystem:   mockFileSystem,
+			mockRangeMetrics: mockBulkRangeMetrics.mockRangeMetrics,
+		}
+	})
+
+	o.Spec("it uses the bulk metrics to split the range", func(t TB) {
+		files := stripRand(toSlice(t.mockFileSystem.CreateInput.File, 2))
+		Expect(t, files).To(Contain(
+			buildRangeName(9223372036854775808, 13835058055282163711, 2),
+			buildRangeName(13835058055282163712, 18446744073709551615, 3),
+		))
+	})
+
+	o.Spec("it does not query each file", func(t TB) {
+		Expect(t, t.mockRangeMetrics.MetricsCalled).To(Always(HaveLen(0)))
+	})
+}
+
+func TestBalancerValidatesChanges(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it does not split over a range without metrics", func(t *testing.T) {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+		maintainer.StartBalancer(mockRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+		)
+
+		files := []string{
+			buildRangeName(0, 9223372036854775807, 0),
+			buildRangeName(9223372036854775808, 18446744073709551615, 1),
+			buildRangeName(0, 4611686018427387903, 2),
+		}
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, files)
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		go func() {
+			for file := range mockRangeMetrics.MetricsInput.File {
+				switch file {
+				case files[0]:
+					mockRangeMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: 2600}
+					mockRangeMetrics.MetricsOutput.Err <- nil
+				case files[1]:
+					mockRangeMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: 25}
+					mockRangeMetrics.MetricsOutput.Err <- nil
+				default:
+					mockRangeMetrics.MetricsOutput.Metric <- router.Metric{}
+					mockRangeMetrics.MetricsOutput.Err <- fmt.Errorf("some-error")
+				}
+			}
+		}()
+
+		for i := 0; i < 9; i++ {
+			Expect(t, mockRangeMetrics.MetricsCalled).To(ViaPolling(Receive()))
+		}
+		Expect(t, mockFileSystem.CreateCalled).To(Always(HaveLen(0)))
+	})
+}
+
+func TestBalancerBulkUnsupported(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it falls back to each file's metrics without logging an error", func(t *testing.T) {
+		mockFileSystem := newMockFileSystem()
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, []string{
+			buildRangeName(0, 18446744073709551615, 0),
+		})
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		mockBulkRangeMetrics := newMockBulkRangeMetrics()
+		close(mockBulkRangeMetrics.BulkMetricsOutput.Metrics)
+		testhelpers.AlwaysReturn(mockBulkRangeMetrics.BulkMetricsOutput.Err, metrics.ErrBulkUnsupported)
+		testhelpers.AlwaysReturn(mockBulkRangeMetrics.MetricsOutput.Metric, router.Metric{WriteCount: 2600})
+		close(mockBulkRangeMetrics.MetricsOutput.Err)
+
+		logs := &syncBuffer{}
+		maintainer.StartBalancer(mockBulkRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+			maintainer.WithBalancerLogger(slog.New(slog.NewTextHandler(logs, nil))),
+		)
+
+		Expect(t, mockFileSystem.CreateCalled).To(ViaPolling(Receive()))
+		Expect(t, logs.String()).To(Not(ContainSubstring("bulk")))
+	})
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestBalancerPartialMetrics(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		mockFileSystem := newMockFileSystem()
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, []string{
+			buildRangeName(0, 9223372036854775807, 0),
+			buildRangeName(9223372036854775808, 18446744073709551615, 1),
+		})
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		return TB{
+			T:              t,
+			mockFileSystem: mockFileSystem,
+		}
+	})
+
+	o.Spec("it splits ranges while routers are missing", func(t TB) {
+		mockPartialRangeMetrics := newMockPartialRangeMetrics()
+		testhelpers.AlwaysReturn(mockPartialRangeMetrics.PartialMetricsOutput.Metric, router.Metric{WriteCount: 2600})
+		testhelpers.AlwaysReturn(mockPartialRangeMetrics.PartialMetricsOutput.Missing, []string{"some-router"})
+		close(mockPartialRangeMetrics.PartialMetricsOutput.Err)
+
+		logs := &syncBuffer{}
+		maintainer.StartBalancer(mockPartialRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+			maintainer.WithBalancerLogger(slog.New(slog.NewTextHandler(logs, nil))),
+		)
+
+		Expect(t, t.mockFileSystem.CreateCalled).To(ViaPolling(Receive()))
+		Expect(t, logs.String()).To(ContainSubstring("some-router"))
+	})
+
+	o.Spec("it splits ranges once every router reports", func(t TB) {
+		mockPartialRangeMetrics := newMockPartialRangeMetrics()
+		testhelpers.AlwaysReturn(mockPartialRangeMetrics.PartialMetricsOutput.Metric, router.Metric{WriteCount: 2600})
+		close(mockPartialRangeMetrics.PartialMetricsOutput.Missing)
+		close(mockPartialRangeMetrics.PartialMetricsOutput.Err)
+
+		maintainer.StartBalancer(mockPartialRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+		)
+
+		Expect(t, t.mockFileSystem.CreateCalled).To(ViaPolling(Receive()))
+	})
+
+	o.Spec("it splits ranges while routers are missing from bulk metrics", func(t TB) {
+		mockPartialBulkRangeMetrics := newMockPartialBulkRangeMetrics()
+		testhelpers.AlwaysReturn(mockPartialBulkRangeMetrics.PartialBulkMetricsOutput.Metrics, map[string]router.Metric{
+			buildRangeName(0, 9223372036854775807, 0): {WriteCount: 2600},
+		})
+		testhelpers.AlwaysReturn(mockPartialBulkRangeMetrics.PartialBulkMetricsOutput.Missing, []string{"some-router"})
+		close(mockPartialBulkRangeMetrics.PartialBulkMetricsOutput.Err)
+
+		maintainer.StartBalancer(mockPartialBulkRangeMetrics, t.mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+		)
+
+		files := stripRand(toSlice(t.mockFileSystem.CreateInput.File, 2))
+		Expect(t, files).To(Contain(
+			buildRangeName(0, 4611686018427387903, 2),
+			buildRangeName(4611686018427387904, 9223372036854775807, 3),
+		))
+	})
+}
+
 func TestBalancerMaxCounts(t *testing.T) {
 	t.Parallel()
 	o := onpar.New()
@@ -295,6 +1008,78 @@ func TestBalancerEmptyRanges(t *testing.T) {
 	})
 }
 
+func TestBalancerTopic(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it seeds the topic independently of other topics", func(t *testing.T) {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+		topic, err := router.ParseTopic("some-topic")
+		Expect(t, err == nil).To(BeTrue())
+
+		maintainer.StartBalancer(mockRangeMetrics, mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(1),
+			maintainer.WithBalancerTopic(topic),
+		)
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, []string{
+			"other-topic/" + buildRangeName(0, 18446744073709551615, 0),
+		})
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		files := toSlice(mockFileSystem.CreateInput.File, 1)
+		Expect(t, files[0]).To(StartWith("some-topic/"))
+
+		codec, err := router.NewTopicCodec("some-topic", router.JSONCodec{})
+		Expect(t, err == nil).To(BeTrue())
+
+		rn, err := codec.Decode(files[0])
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, rn.Low).To(Equal(uint64(0)))
+		Expect(t, rn.High).To(Equal(uint64(18446744073709551615)))
+
+		Expect(t, mockRangeMetrics.MetricsCalled).To(Always(HaveLen(0)))
+	})
+}
+
+func TestBalancerObserver(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it sends an event for each seeded range", func(t *testing.T) {
+		mockFileSystem := newMockFileSystem()
+		events := make(chan maintainer.Event, 100)
+		maintainer.StartBalancer(newMockRangeMetrics(), mockFileSystem,
+			maintainer.WithBalancerInterval(time.Millisecond),
+			maintainer.WithMinCount(2),
+			maintainer.WithBalancerObserver(maintainer.ObserverFunc(func(e maintainer.Event) {
+				events <- e
+			})),
+		)
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, []string{})
+		close(mockFileSystem.ListOutput.Err)
+		mockFileSystem.CreateOutput.Err <- nil
+		mockFileSystem.CreateOutput.Err <- fmt.Errorf("some-error")
+
+		seeded, ok := receiveEvent(t, events).(maintainer.RangeSeeded)
+		Expect(t, ok).To(BeTrue())
+		Expect(t, seeded.Range.Low).To(Equal(uint64(0)))
+		Expect(t, seeded.Range.High).To(Equal(uint64(9223372036854775807)))
+
+		failed, ok := receiveEvent(t, events).(maintainer.ActionFailed)
+		Expect(t, ok).To(BeTrue())
+		Expect(t, failed.Action).To(Equal(maintainer.ActionSeed))
+		Expect(t, failed.Range.Term).To(Equal(uint64(1)))
+		Expect(t, failed.Err).To(Not(BeNil()))
+	})
+}
+
 func serviceMetrics(t TB, repeater chan string, m map[string]uint64) {
 	for file := range t.mockRangeMetrics.MetricsInput.File {
 		t.mockRangeMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: m[file]}
@@ -303,6 +1088,37 @@ func serviceMetrics(t TB, repeater chan string, m map[string]uint64) {
 	}
 }
 
+// serviceMetricSequences answers each file's metrics with the next write
+// count in its sequence, repeating the last one once the sequence runs out.
+// Each queried file is sent to the optional repeater.
+func serviceMetricSequences(m *mockRangeMetrics, sequences map[string][]uint64, repeater ...chan string) {
+	calls := make(map[string]int)
+	for file := range m.MetricsInput.File {
+		seq := sequences[file]
+
+		var writes uint64
+		if len(seq) > 0 {
+			writes = seq[min(calls[file], len(seq)-1)]
+		}
+		calls[file]++
+
+		m.MetricsOutput.Metric <- router.Metric{WriteCount: writes}
+		m.MetricsOutput.Err <- nil
+
+		for _, r := range repeater {
+			r <- file
+		}
+	}
+}
+
+func serviceFullMetrics(t TB, repeater chan string, m map[string]router.Metric) {
+	for file := range t.mockRangeMetrics.MetricsInput.File {
+		t.mockRangeMetrics.MetricsOutput.Metric <- m[file]
+		t.mockRangeMetrics.MetricsOutput.Err <- nil
+		repeater <- file
+	}
+}
+
 func stripRand(s []string) (results []string) {
 	for _, ss := range s {
 		var rn router.RangeName
@@ -343,3 +1159,65 @@ func buildRangeName(low, high, term uint64) string {
 	j, _ := json.Marshal(rn)
 	return string(j)
 }
+
+type createdFile struct {
+	file string
+	tick int
+}
+
+// serviceFileSystem answers each List with the given files and every file
+// created since. Each created file is sent along with the number of times
+// List was called before it was created.
+func serviceFileSystem(m *mockFileSystem, files []string) chan createdFile {
+	var (
+		mu    sync.Mutex
+		ticks int
+	)
+	close(m.ListOutput.Err)
+
+	go func() {
+		for range m.ListCalled {
+			mu.Lock()
+			ticks++
+			m.ListOutput.File <- append([]string(nil), files...)
+			mu.Unlock()
+		}
+	}()
+
+	created := make(chan createdFile, 100)
+	go func() {
+		for file := range m.CreateInput.File {
+			mu.Lock()
+			files = append(files, file)
+			created <- createdFile{file: file, tick: ticks}
+			mu.Unlock()
+			m.CreateOutput.Err <- nil
+		}
+	}()
+
+	return created
+}
+
+func receiveCreate(t *testing.T, created chan createdFile) createdFile {
+	t.Helper()
+
+	select {
+	case c := <-created:
+		return c
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a range to be created")
+		return createdFile{}
+	}
+}
+
+func receiveEvent(t *testing.T, events chan maintainer.Event) maintainer.Event {
+	t.Helper()
+
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for an event")
+		return nil
+	}
+}
@@ -1,13 +1,13 @@
 package maintainer
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"log/slog"
 	"math/rand"
-	"sort"
 	"time"
 
 	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
 )
 
 type Filler struct {
@@ -19,6 +19,11 @@ type Filler struct {
 type fillerConfig struct {
 	min      uint64
 	interval time.Duration
+	codec    router.RangeNameCodec
+	topic    router.Topic
+	observer Observer
+	tracer   router.Tracer
+	logger   *slog.Logger
 }
 
 type FillerOpts func(c *fillerConfig)
@@ -27,12 +32,18 @@ func StartFiller(rangeMetrics RangeMetrics, fs FileSystem, opts ...FillerOpts) *
 	conf := fillerConfig{
 		interval: 5 * time.Second,
 		min:      3,
+		codec:    router.JSONCodec{},
+		observer: nopObserver{},
+		tracer:   router.NopTracer{},
+		logger:   slog.Default(),
 	}
 
 	for _, opt := range opts {
 		opt(&conf)
 	}
 
+	conf.codec = conf.topic.Codec(conf.codec)
+
 	f := &Filler{
 		conf:         conf,
 		rangeMetrics: rangeMetrics,
@@ -45,53 +56,55 @@ func StartFiller(rangeMetrics RangeMetrics, fs FileSystem, opts ...FillerOpts) *
 
 func (f *Filler) run() {
 	for range time.Tick(f.conf.interval) {
-		ranges, actual, lastTerm, _ := validRanges(f.fs, f.rangeMetrics)
-		if uint64(len(actual)) < f.conf.min {
+		list, _ := validRanges(f.fs, f.rangeMetrics, f.conf.codec, f.conf.logger)
+		if uint64(len(list.actual)) < f.conf.min {
 			continue
 		}
 
-		sort.Sort(rangeInfos(ranges))
-
-		if gap, foundOne := f.findGap(0, 18446744073709551615, ranges); foundOne {
-			f.fillGap(gap, lastTerm)
+		if gap, foundOne := f.findGap(list.ranges); foundOne {
+			f.fillGap(gap, list.snapshot.LastTerm())
 			continue
 		}
 	}
 }
 
-// findGap takes only valid ranges
-func (f *Filler) findGap(start, end uint64, ranges []rangeInfo) (router.RangeName, bool) {
-	var gapEnd uint64 = 18446744073709551615
+// findGap returns the first span of hashes the valid ranges do not cover.
+func (f *Filler) findGap(ranges []rangeInfo) (router.RangeName, bool) {
+	files := make([]string, 0, len(ranges))
 	for _, x := range ranges {
-		if x.hashRange.Low == start {
-			if x.hashRange.High == 18446744073709551615 {
-				return router.RangeName{}, false
-			}
-
-			return f.findGap(x.hashRange.High+1, end, ranges)
-		}
+		files = append(files, x.file)
+	}
 
-		if x.hashRange.Low > start && x.hashRange.Low < gapEnd {
-			gapEnd = x.hashRange.Low - 1
-		}
+	gaps := topology.New(files, f.conf.codec).Gaps()
+	if len(gaps) == 0 {
+		return router.RangeName{}, false
 	}
 
 	return router.RangeName{
-		Low:  start,
-		High: gapEnd,
+		Low:  gaps[0].Low,
+		High: gaps[0].High,
 		Rand: rand.Int63(),
 	}, true
 }
 
 func (f *Filler) fillGap(gap router.RangeName, lastTerm uint64) {
-	log.Printf("Filling gap (%d - %d)", gap.Low, gap.High)
-	defer log.Printf("Done filling gap (%d - %d)...", gap.Low, gap.High)
+	f.conf.logger.Info("filling gap", "low", gap.Low, "high", gap.High)
+	defer f.conf.logger.Info("done filling gap", "low", gap.Low, "high", gap.High)
 
 	gap.Term = lastTerm + 1
 
-	gapName, _ := json.Marshal(gap)
+	gapName := f.conf.codec.Encode(gap)
 
-	if err := f.fs.Create(string(gapName)); err != nil {
-		log.Printf("Error creating file %s to read only: %s", string(gapName), err)
+	_, span := f.conf.tracer.Start(context.Background(), "petasos.filler.FillGap")
+	defer span.End()
+	span.SetRange(gap)
+
+	if err := f.fs.Create(gapName); err != nil {
+		f.conf.logger.Error("failed to create range", "range", gap, "file", gapName, "err", err)
+		span.RecordError(err)
+		f.conf.observer.Observe(ActionFailed{Action: ActionFillGap, Range: gap, Err: err})
+		return
 	}
+
+	f.conf.observer.Observe(GapFilled{Gap: gap})
 }
@@ -201,6 +201,49 @@ func TestFillerMidWayGap(t *testing.T) {
 	})
 }
 
+func TestFillerSharedBound(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TB {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+		maintainer.StartFiller(mockRangeMetrics, mockFileSystem,
+			maintainer.WithFillerInterval(time.Millisecond),
+			maintainer.WithFillerMinCount(2),
+		)
+
+		files := []string{
+			buildRangeName(0, 100, 1),                    // Shares 100 with the newer range
+			buildRangeName(100, 18446744073709551615, 2), // Valid
+		}
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, files)
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		tb := TB{
+			T: t,
+
+			files:            files,
+			repeatedFiles:    make(chan string, 100),
+			mockFileSystem:   mockFileSystem,
+			mockRangeMetrics: mockRangeMetrics,
+		}
+		go serviceMetrics(tb, tb.repeatedFiles, map[string]uint64{
+			files[0]: 25,
+			files[1]: 25,
+		})
+
+		return tb
+	})
+
+	o.Spec("it keeps a range that only shares a bound with a newer one", func(t TB) {
+		Expect(t, t.mockFileSystem.CreateCalled).To(Always(HaveLen(0)))
+	})
+}
+
 func TestFillerGapFromErrs(t *testing.T) {
 	t.Parallel()
 	o := onpar.New()
@@ -254,3 +297,47 @@ func serviceMetricsWithErrs(t TB, repeater chan string, m map[string]router.Metr
 		repeater <- file
 	}
 }
+
+func TestFillerObserver(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it sends an event for the filled gap", func(t *testing.T) {
+		mockFileSystem := newMockFileSystem()
+		mockRangeMetrics := newMockRangeMetrics()
+		events := make(chan maintainer.Event, 100)
+		maintainer.StartFiller(mockRangeMetrics, mockFileSystem,
+			maintainer.WithFillerInterval(time.Millisecond),
+			maintainer.WithFillerMinCount(2),
+			maintainer.WithFillerObserver(maintainer.ObserverFunc(func(e maintainer.Event) {
+				events <- e
+			})),
+		)
+
+		files := []string{
+			buildRangeName(0, 9223372036854775807, 0),
+			buildRangeName(9223372036854775809, 18446744073709551615, 1),
+		}
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, files)
+		close(mockFileSystem.ListOutput.Err)
+		close(mockFileSystem.CreateOutput.Err)
+
+		tb := TB{
+			T:                t,
+			repeatedFiles:    make(chan string, 100),
+			mockRangeMetrics: mockRangeMetrics,
+		}
+		go serviceMetrics(tb, tb.repeatedFiles, map[string]uint64{
+			files[0]: 25,
+			files[1]: 25,
+		})
+
+		filled, ok := receiveEvent(t, events).(maintainer.GapFilled)
+		Expect(t, ok).To(BeTrue())
+		Expect(t, filled.Gap.Low).To(Equal(uint64(9223372036854775808)))
+		Expect(t, filled.Gap.High).To(Equal(uint64(9223372036854775808)))
+		Expect(t, filled.Gap.Term).To(Equal(uint64(2)))
+	})
+}
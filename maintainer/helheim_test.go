@@ -66,3 +66,78 @@ func (m *mockFileSystem) List() (file []string, err error) {
 	m.ListCalled <- true
 	return <-m.ListOutput.File, <-m.ListOutput.Err
 }
+
+type mockBulkRangeMetrics struct {
+	*mockRangeMetrics
+	BulkMetricsCalled chan bool
+	BulkMetricsOutput struct {
+		Metrics chan map[string]router.Metric
+		Err     chan error
+	}
+}
+
+func newMockBulkRangeMetrics() *mockBulkRangeMetrics {
+	m := &mockBulkRangeMetrics{}
+	m.mockRangeMetrics = newMockRangeMetrics()
+	m.BulkMetricsCalled = make(chan bool, 100)
+	m.BulkMetricsOutput.Metrics = make(chan map[string]router.Metric, 100)
+	m.BulkMetricsOutput.Err = make(chan error, 100)
+	return m
+}
+func (m *mockBulkRangeMetrics) BulkMetrics() (metrics map[string]router.Metric, err error) {
+	m.BulkMetricsCalled <- true
+	return <-m.BulkMetricsOutput.Metrics, <-m.BulkMetricsOutput.Err
+}
+
+type mockPartialRangeMetrics struct {
+	*mockRangeMetrics
+	PartialMetricsCalled chan bool
+	PartialMetricsInput  struct {
+		File chan string
+	}
+	PartialMetricsOutput struct {
+		Metric  chan router.Metric
+		Missing chan []string
+		Err     chan error
+	}
+}
+
+func newMockPartialRangeMetrics() *mockPartialRangeMetrics {
+	m := &mockPartialRangeMetrics{}
+	m.mockRangeMetrics = newMockRangeMetrics()
+	m.PartialMetricsCalled = make(chan bool, 100)
+	m.PartialMetricsInput.File = make(chan string, 100)
+	m.PartialMetricsOutput.Metric = make(chan router.Metric, 100)
+	m.PartialMetricsOutput.Missing = make(chan []string, 100)
+	m.PartialMetricsOutput.Err = make(chan error, 100)
+	return m
+}
+func (m *mockPartialRangeMetrics) PartialMetrics(file string) (metric router.Metric, missing []string, err error) {
+	m.PartialMetricsCalled <- true
+	m.PartialMetricsInput.File <- file
+	return <-m.PartialMetricsOutput.Metric, <-m.PartialMetricsOutput.Missing, <-m.PartialMetricsOutput.Err
+}
+
+type mockPartialBulkRangeMetrics struct {
+	*mockRangeMetrics
+	PartialBulkMetricsCalled chan bool
+	PartialBulkMetricsOutput struct {
+		Metrics chan map[string]router.Metric
+		Missing chan []string
+		Err     chan error
+	}
+}
+
+func newMockPartialBulkRangeMetrics() *mockPartialBulkRangeMetrics {
+	m := &mockPartialBulkRangeMetrics{}
+	m.mockRangeMetrics = newMockRangeMetrics()
+	m.PartialBulkMetricsCalled = make(chan bool, 100)
+	m.PartialBulkMetricsOutput.Metrics = make(chan map[string]router.Metric, 100)
+	m.PartialBulkMetricsOutput.Missing = make(chan []string, 100)
+	m.PartialBulkMetricsOutput.Err = make(chan error, 100)
+	return m
+}
+func (m *mockPartialBulkRangeMetrics) PartialBulkMetrics() (metrics map[string]router.Metric, missing []string, err error) {
+	m.PartialBulkMetricsCalled <- true
+	return <-m.PartialBulkMetricsOutput.Metrics, <-m.PartialBulkMetricsOutput.Missing, <-m.PartialBulkMetricsOutput.Err
+}
@@ -0,0 +1,60 @@
+package maintainer
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+
+	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
+)
+
+// SplitRange returns the halves the balancer splits parent into. They take
+// the two terms after lastTerm.
+func SplitRange(parent router.RangeName, lastTerm uint64) (low, high router.RangeName) {
+	middle := (parent.High-parent.Low)/2 + parent.Low
+	low = router.RangeName{
+		Term: lastTerm + 1,
+		Low:  parent.Low,
+		High: middle,
+		Rand: rand.Int63(),
+	}
+
+	high = router.RangeName{
+		Term: lastTerm + 2,
+		Low:  middle + 1,
+		High: parent.High,
+		Rand: rand.Int63(),
+	}
+
+	return low, high
+}
+
+// CombineRanges returns the range the balancer combines x and y into. It
+// takes the term after lastTerm. x and y must be adjacent.
+func CombineRanges(x, y router.RangeName, lastTerm uint64) (combined router.RangeName, err error) {
+	if !adjacent(x, y) && !adjacent(y, x) {
+		return router.RangeName{}, fmt.Errorf("%d-%d and %d-%d are not adjacent", x.Low, x.High, y.Low, y.High)
+	}
+
+	return router.RangeName{
+		Term: lastTerm + 1,
+		Low:  min(x.Low, y.Low),
+		High: max(x.High, y.High),
+		Rand: rand.Int63(),
+	}, nil
+}
+
+// ValidateChange ensures the new range only covers hashes owned by the
+// replaced files. Any other range would otherwise be swallowed by the
+// newer term, even if the balancer skipped it (e.g., its metrics were
+// unavailable).
+func ValidateChange(newRange router.RangeName, snapshot *topology.Snapshot, replaced ...string) error {
+	for _, owner := range snapshot.Owners(newRange.Low, newRange.High) {
+		if !slices.Contains(replaced, owner.File) {
+			return fmt.Errorf("%d-%d would cover %s", newRange.Low, newRange.High, owner.File)
+		}
+	}
+
+	return nil
+}
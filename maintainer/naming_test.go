@@ -0,0 +1,69 @@
+package maintainer_test
+
+import (
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/maintainer"
+	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
+)
+
+func TestNaming(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it splits a range in half with the next two terms", func(t *testing.T) {
+		low, high := maintainer.SplitRange(router.RangeName{Low: 0, High: 9223372036854775807, Term: 2}, 3)
+
+		Expect(t, low.Low).To(Equal(uint64(0)))
+		Expect(t, low.High).To(Equal(uint64(4611686018427387903)))
+		Expect(t, low.Term).To(Equal(uint64(4)))
+
+		Expect(t, high.Low).To(Equal(uint64(4611686018427387904)))
+		Expect(t, high.High).To(Equal(uint64(9223372036854775807)))
+		Expect(t, high.Term).To(Equal(uint64(5)))
+	})
+
+	o.Spec("it combines adjacent ranges with the next term", func(t *testing.T) {
+		combined, err := maintainer.CombineRanges(
+			router.RangeName{Low: 11, High: 20},
+			router.RangeName{Low: 0, High: 10},
+			3,
+		)
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, combined.Low).To(Equal(uint64(0)))
+		Expect(t, combined.High).To(Equal(uint64(20)))
+		Expect(t, combined.Term).To(Equal(uint64(4)))
+	})
+
+	o.Spec("it does not combine ranges that are not adjacent", func(t *testing.T) {
+		_, err := maintainer.CombineRanges(
+			router.RangeName{Low: 0, High: 10},
+			router.RangeName{Low: 12, High: 20},
+			3,
+		)
+		Expect(t, err == nil).To(BeFalse())
+	})
+
+	o.Spec("it allows a change that only covers the replaced ranges", func(t *testing.T) {
+		x := router.JSONCodec{}.Encode(router.RangeName{Low: 0, High: 10, Term: 1})
+		y := router.JSONCodec{}.Encode(router.RangeName{Low: 11, High: 20, Term: 2})
+		snapshot := topology.New([]string{x, y}, router.JSONCodec{})
+
+		err := maintainer.ValidateChange(router.RangeName{Low: 0, High: 20, Term: 3}, snapshot, x, y)
+		Expect(t, err == nil).To(BeTrue())
+	})
+
+	o.Spec("it refuses a change that covers another range", func(t *testing.T) {
+		x := router.JSONCodec{}.Encode(router.RangeName{Low: 0, High: 10, Term: 1})
+		y := router.JSONCodec{}.Encode(router.RangeName{Low: 11, High: 20, Term: 2})
+		snapshot := topology.New([]string{x, y}, router.JSONCodec{})
+
+		err := maintainer.ValidateChange(router.RangeName{Low: 0, High: 20, Term: 3}, snapshot, x)
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
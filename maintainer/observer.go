@@ -0,0 +1,73 @@
+package maintainer
+
+import "github.com/poy/petasos/router"
+
+// Observer is told about every change the maintainers make, or fail to
+// make, to the topology. Observe is called from the maintainer's goroutine
+// and should not block.
+type Observer interface {
+	Observe(event Event)
+}
+
+// ObserverFunc adapts a func to an Observer.
+type ObserverFunc func(event Event)
+
+func (f ObserverFunc) Observe(event Event) {
+	f(event)
+}
+
+// Event is one of RangeSeeded, RangeSplit, RangesCombined, GapFilled or
+// ActionFailed.
+type Event interface {
+	event()
+}
+
+// RangeSeeded is sent for each range the balancer creates when there are
+// none.
+type RangeSeeded struct {
+	Range router.RangeName
+}
+
+// RangeSplit is sent when the balancer splits Parent into Low and High.
+type RangeSplit struct {
+	Parent, Low, High router.RangeName
+}
+
+// RangesCombined is sent when the balancer combines First and Next into
+// Combined.
+type RangesCombined struct {
+	First, Next, Combined router.RangeName
+}
+
+// GapFilled is sent when the filler creates Gap to cover hashes without a
+// range.
+type GapFilled struct {
+	Gap router.RangeName
+}
+
+// Action names what a maintainer was doing when it failed.
+type Action string
+
+const (
+	ActionSeed    Action = "seed"
+	ActionSplit   Action = "split"
+	ActionCombine Action = "combine"
+	ActionFillGap Action = "fill_gap"
+)
+
+// ActionFailed is sent when creating Range fails.
+type ActionFailed struct {
+	Action Action
+	Range  router.RangeName
+	Err    error
+}
+
+func (RangeSeeded) event()    {}
+func (RangeSplit) event()     {}
+func (RangesCombined) event() {}
+func (GapFilled) event()      {}
+func (ActionFailed) event()   {}
+
+type nopObserver struct{}
+
+func (nopObserver) Observe(event Event) {}
@@ -1,6 +1,11 @@
 package maintainer
 
-import "time"
+import (
+	"log/slog"
+	"time"
+
+	"github.com/poy/petasos/router"
+)
 
 func WithFillerInterval(interval time.Duration) func(c *fillerConfig) {
 	return func(c *fillerConfig) {
@@ -43,3 +48,143 @@ func WithMaxCount(count uint64) func(c *balancerConfig) {
 		c.max = count
 	}
 }
+
+func WithMaxActionsPerInterval(actions uint64) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.maxActions = actions
+	}
+}
+
+// WithCooldownIntervals keeps the balancer from splitting or combining a
+// range it created within the last given number of intervals. It defaults
+// to 3 so the halves of a split are not combined straight away.
+func WithCooldownIntervals(intervals uint64) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.cooldown = intervals
+	}
+}
+
+// WithSmoothing sets the weight (0 < alpha <= 1) given to the latest
+// interval when averaging each range's write count. An alpha of 1 disables
+// smoothing.
+func WithSmoothing(alpha float64) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.smoothing = alpha
+	}
+}
+
+// WithHysteresisIntervals requires a range to stay above the split
+// threshold, or below the combine threshold, for the given number of
+// consecutive intervals before the balancer acts on it. It defaults to 2.
+func WithHysteresisIntervals(intervals uint64) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.hysteresis = intervals
+	}
+}
+
+func WithBalanceOn(balanceOn BalanceOn) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.balanceOn = balanceOn
+	}
+}
+
+func WithMaxBytesPerInterval(bytes uint64) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.maxBytesPerInterval = bytes
+	}
+}
+
+func WithMinBytesPerInterval(bytes uint64) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.minBytesPerInterval = bytes
+	}
+}
+
+// WithMaxLatency keeps the balancer from splitting or combining ranges whose
+// p99 write latency is above the given duration. A duration of 0 (the
+// default) disables the check.
+func WithMaxLatency(latency time.Duration) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.maxLatency = latency
+	}
+}
+
+// WithBalancerObserver sets the Observer told about every seed, split and
+// combine. It defaults to doing nothing.
+func WithBalancerObserver(observer Observer) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.observer = observer
+	}
+}
+
+// WithFillerObserver sets the Observer told about every gap filled. It
+// defaults to doing nothing.
+func WithFillerObserver(observer Observer) func(c *fillerConfig) {
+	return func(c *fillerConfig) {
+		c.observer = observer
+	}
+}
+
+// WithBalancerTracer sets the Tracer used to span every seed, split and
+// combine. It defaults to router.NopTracer.
+func WithBalancerTracer(tracer router.Tracer) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.tracer = tracer
+	}
+}
+
+// WithFillerTracer sets the Tracer used to span every gap filled. It
+// defaults to router.NopTracer.
+func WithFillerTracer(tracer router.Tracer) func(c *fillerConfig) {
+	return func(c *fillerConfig) {
+		c.tracer = tracer
+	}
+}
+
+// WithBalancerLogger sets the balancer's logger. It defaults to
+// slog.Default().
+func WithBalancerLogger(logger *slog.Logger) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.logger = logger
+	}
+}
+
+// WithFillerLogger sets the filler's logger. It defaults to slog.Default().
+func WithFillerLogger(logger *slog.Logger) func(c *fillerConfig) {
+	return func(c *fillerConfig) {
+		c.logger = logger
+	}
+}
+
+// WithBalancerCodec sets the codec used to encode and decode range file
+// names. It defaults to router.JSONCodec.
+func WithBalancerCodec(codec router.RangeNameCodec) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.codec = codec
+	}
+}
+
+// WithFillerCodec sets the codec used to encode and decode range file names.
+// It defaults to router.JSONCodec.
+func WithFillerCodec(codec router.RangeNameCodec) func(c *fillerConfig) {
+	return func(c *fillerConfig) {
+		c.codec = codec
+	}
+}
+
+// WithBalancerTopic scopes the balancer to the topic's range set (see
+// router.TopicCodec). Start a balancer per topic to balance each with its
+// own config.
+func WithBalancerTopic(topic router.Topic) func(c *balancerConfig) {
+	return func(c *balancerConfig) {
+		c.topic = topic
+	}
+}
+
+// WithFillerTopic scopes the filler to the topic's range set (see
+// router.TopicCodec).
+func WithFillerTopic(topic router.Topic) func(c *fillerConfig) {
+	return func(c *fillerConfig) {
+		c.topic = topic
+	}
+}
@@ -0,0 +1,80 @@
+package maintainer
+
+// smoother keeps an exponentially weighted moving average of each range's
+// write and byte counts along with how many consecutive intervals it has
+// spent above the split threshold or below the combine threshold.
+type smoother struct {
+	alpha float64
+	stats map[string]*rangeStats
+}
+
+type rangeStats struct {
+	writes, bytes float64
+	hot, cold     uint64
+}
+
+func newSmoother(alpha float64) *smoother {
+	return &smoother{
+		alpha: alpha,
+		stats: make(map[string]*rangeStats),
+	}
+}
+
+// smooth replaces each write and byte count with its moving average and
+// updates the hot and cold streaks. Stats for ranges that are no longer
+// given are dropped.
+func (s *smoother) smooth(ranges []rangeInfo, isHot, isCold func(rangeInfo) bool) {
+	seen := make(map[string]bool)
+	for i, r := range ranges {
+		seen[r.file] = true
+
+		stats, ok := s.stats[r.file]
+		if !ok {
+			stats = &rangeStats{
+				writes: float64(r.writeCount),
+				bytes:  float64(r.byteCount),
+			}
+			s.stats[r.file] = stats
+		} else {
+			stats.writes = s.average(stats.writes, r.writeCount)
+			stats.bytes = s.average(stats.bytes, r.byteCount)
+		}
+
+		ranges[i].writeCount = uint64(stats.writes + 0.5)
+		ranges[i].byteCount = uint64(stats.bytes + 0.5)
+
+		stats.hot = streak(stats.hot, isHot(ranges[i]))
+		stats.cold = streak(stats.cold, isCold(ranges[i]))
+	}
+
+	for file := range s.stats {
+		if !seen[file] {
+			delete(s.stats, file)
+		}
+	}
+}
+
+func (s *smoother) average(avg float64, value uint64) float64 {
+	return s.alpha*float64(value) + (1-s.alpha)*avg
+}
+
+func (s *smoother) hot(file string) uint64 {
+	if stats, ok := s.stats[file]; ok {
+		return stats.hot
+	}
+	return 0
+}
+
+func (s *smoother) cold(file string) uint64 {
+	if stats, ok := s.stats[file]; ok {
+		return stats.cold
+	}
+	return 0
+}
+
+func streak(count uint64, ok bool) uint64 {
+	if !ok {
+		return 0
+	}
+	return count + 1
+}
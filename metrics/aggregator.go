@@ -1,26 +1,152 @@
 package metrics
 
-import "github.com/poy/petasos/router"
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/poy/petasos/router"
+)
 
 type Router interface {
 	Metrics(file string) (metric router.Metric)
 }
 
 type Aggregator struct {
-	routers []Router
+	routers func() []Router
 }
 
 func NewAggregator(routers []Router) *Aggregator {
 	return &Aggregator{
-		routers: routers,
+		routers: func() []Router {
+			return routers
+		},
+	}
+}
+
+type aggregatorConfig struct {
+	refresh time.Duration
+	logger  *slog.Logger
+}
+
+type AggregatorOpts func(c *aggregatorConfig)
+
+// NewDiscoveringAggregator returns an Aggregator that asks the Discoverer
+// for the current routers at most once per refresh interval. dial is called
+// once for each new address and routers that are no longer discovered are
+// closed if they implement io.Closer or have a Close method. If the
+// Discoverer fails, the last known routers are used.
+func NewDiscoveringAggregator(discoverer Discoverer, dial func(addr string) Router, opts ...AggregatorOpts) *Aggregator {
+	conf := aggregatorConfig{
+		refresh: 5 * time.Second,
+		logger:  slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	s := &routerSet{
+		discoverer: discoverer,
+		dial:       dial,
+		refresh:    conf.refresh,
+		logger:     conf.logger,
+		routers:    make(map[string]Router),
+	}
+
+	return &Aggregator{
+		routers: s.current,
 	}
 }
 
 func (a *Aggregator) Metrics(file string) (metric router.Metric) {
-	for _, r := range a.routers {
-		m := r.Metrics(file)
-		metric.WriteCount += m.WriteCount
-		metric.ErrCount += m.ErrCount
+	for _, r := range a.routers() {
+		metric = add(metric, r.Metrics(file))
 	}
 	return metric
 }
+
+// BulkMetrics returns the summed metrics for every range file. Each Router
+// must implement BulkRouter.
+func (a *Aggregator) BulkMetrics() (metrics map[string]router.Metric, err error) {
+	metrics = make(map[string]router.Metric)
+	for _, r := range a.routers() {
+		bulk, ok := r.(BulkRouter)
+		if !ok {
+			return nil, ErrBulkUnsupported
+		}
+
+		for file, metric := range bulk.BulkMetrics() {
+			metrics[file] = add(metrics[file], metric)
+		}
+	}
+	return metrics, nil
+}
+
+type routerSet struct {
+	discoverer Discoverer
+	dial       func(addr string) Router
+	refresh    time.Duration
+	logger     *slog.Logger
+
+	mu           sync.Mutex
+	routers      map[string]Router
+	discoveredAt time.Time
+}
+
+func (s *routerSet) current() (routers []Router) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.discoveredAt.IsZero() || time.Since(s.discoveredAt) >= s.refresh {
+		s.discover()
+	}
+
+	for _, r := range s.routers {
+		routers = append(routers, r)
+	}
+	return routers
+}
+
+// discover asks the Discoverer for the routers. If it fails, the last known
+// routers are kept until the next refresh.
+func (s *routerSet) discover() {
+	s.discoveredAt = time.Now()
+
+	addrs, err := s.discoverer.Addrs()
+	if err != nil {
+		s.logger.Error("failed to discover routers", "err", err)
+		return
+	}
+
+	s.update(addrs)
+}
+
+func (s *routerSet) update(addrs []string) {
+	seen := make(map[string]bool)
+	for _, addr := range addrs {
+		seen[addr] = true
+
+		if _, ok := s.routers[addr]; !ok {
+			s.routers[addr] = s.dial(addr)
+		}
+	}
+
+	for addr, r := range s.routers {
+		if !seen[addr] {
+			delete(s.routers, addr)
+			s.close(addr, r)
+		}
+	}
+}
+
+func (s *routerSet) close(addr string, r Router) {
+	switch c := r.(type) {
+	case io.Closer:
+		if err := c.Close(); err != nil {
+			s.logger.Warn("failed to close router", "addr", addr, "err", err)
+		}
+	case interface{ Close() }:
+		c.Close()
+	}
+}
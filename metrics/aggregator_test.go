@@ -2,6 +2,7 @@ package metrics_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/poy/eachers/testhelpers"
 	"github.com/poy/onpar"
@@ -56,17 +57,58 @@ func TestAggregator(t *testing.T) {
 		)
 	})
 
+	o.Spec("it merges the latencies", func(t TA) {
+		for i, mr := range t.mockRouters {
+			var h router.Histogram
+			h.Observe(time.Duration(i+1) * time.Millisecond)
+			testhelpers.AlwaysReturn(mr.MetricsOutput.Metric, router.Metric{
+				Latency: h,
+			})
+		}
+		metric := t.agg.Metrics("some-file")
+
+		Expect(t, metric.Latency.Count()).To(Equal(uint64(3)))
+		Expect(t, metric.Latency.Max).To(Equal(3 * time.Millisecond))
+	})
+
 	o.Spec("it sums each metric", func(t TA) {
 		for _, mr := range t.mockRouters {
 			testhelpers.AlwaysReturn(mr.MetricsOutput.Metric, router.Metric{
 				WriteCount: 5,
 				ErrCount:   3,
+				ByteCount:  7,
 			})
 		}
 		metric := t.agg.Metrics("some-file")
 
 		Expect(t, metric.WriteCount).To(Equal(uint64(15)))
 		Expect(t, metric.ErrCount).To(Equal(uint64(9)))
+		Expect(t, metric.ByteCount).To(Equal(uint64(21)))
+
+	})
+}
+
+func TestAggregatorFileCounter(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it accepts router.FileCounters", func(t *testing.T) {
+		rn := router.RangeName{Low: 1, High: 2, Term: 3}
+		codec := router.JSONCodec{}
+
+		var routers []metrics.Router
+		for i := 0; i < 2; i++ {
+			counter := router.NewCounter()
+			counter.IncSuccess(rn, 1)
+			routers = append(routers, router.NewFileCounter(counter, codec))
+		}
+		agg := metrics.NewAggregator(routers)
+
+		Expect(t, agg.Metrics(codec.Encode(rn)).WriteCount).To(Equal(uint64(2)))
 
+		m, err := agg.BulkMetrics()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, m[codec.Encode(rn)].WriteCount).To(Equal(uint64(2)))
 	})
 }
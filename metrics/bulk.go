@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"github.com/poy/petasos/router"
+)
+
+// ErrBulkUnsupported is returned by BulkMetrics when the underlying source
+// can not return every range's metrics at once.
+var ErrBulkUnsupported = errors.New("bulk metrics are not supported")
+
+// BulkMetrics is implemented by Metrics that can return the metrics for
+// every range file in a single call.
+type BulkMetrics interface {
+	BulkMetrics() (metrics map[string]router.Metric, err error)
+}
+
+// BulkNetworkReader is implemented by NetworkReaders that can return the
+// metrics for every range file from a router in a single call.
+type BulkNetworkReader interface {
+	ReadAll(addr string) (metrics map[string]router.Metric, err error)
+}
+
+// ContextBulkNetworkReader is the bulk version of ContextNetworkReader.
+type ContextBulkNetworkReader interface {
+	ReadAllContext(ctx context.Context, addr string) (metrics map[string]router.Metric, err error)
+}
+
+// BulkRouter is implemented by Routers that can return the metrics for
+// every range file at once.
+type BulkRouter interface {
+	BulkMetrics() (metrics map[string]router.Metric)
+}
+
+func add(a, b router.Metric) router.Metric {
+	a.WriteCount += b.WriteCount
+	a.ErrCount += b.ErrCount
+	a.ByteCount += b.ByteCount
+	a.Latency = a.Latency.Merge(b.Latency)
+	return a
+}
@@ -0,0 +1,128 @@
+package metrics_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/poy/eachers/testhelpers"
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/metrics"
+	"github.com/poy/petasos/router"
+)
+
+type TBR struct {
+	*testing.T
+	reader                *metrics.Reader
+	mockBulkNetworkReader *mockBulkNetworkReader
+}
+
+func TestReaderBulkMetrics(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TBR {
+		mockBulkNetworkReader := newMockBulkNetworkReader()
+
+		return TBR{
+			T:                     t,
+			reader:                metrics.NewReader([]string{"a", "b"}, mockBulkNetworkReader),
+			mockBulkNetworkReader: mockBulkNetworkReader,
+		}
+	})
+
+	o.Spec("it sums every file from each router", func(t TBR) {
+		testhelpers.AlwaysReturn(t.mockBulkNetworkReader.ReadAllOutput.Metrics, map[string]router.Metric{
+			"some-file":  {WriteCount: 1},
+			"other-file": {WriteCount: 2},
+		})
+		close(t.mockBulkNetworkReader.ReadAllOutput.Err)
+
+		m, err := t.reader.BulkMetrics()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, m["some-file"].WriteCount).To(Equal(uint64(2)))
+		Expect(t, m["other-file"].WriteCount).To(Equal(uint64(4)))
+
+		s := toSlice(t.mockBulkNetworkReader.ReadAllInput.Addr, 2)
+		Expect(t, s).To(Contain("a", "b"))
+	})
+
+	o.Spec("it returns an error when a router fails", func(t TBR) {
+		close(t.mockBulkNetworkReader.ReadAllOutput.Metrics)
+		testhelpers.AlwaysReturn(t.mockBulkNetworkReader.ReadAllOutput.Err, fmt.Errorf("some-error"))
+
+		_, err := t.reader.BulkMetrics()
+		Expect(t, err == nil).To(BeFalse())
+	})
+
+	o.Spec("it returns an error when the network does not support bulk reads", func(t TBR) {
+		reader := metrics.NewReader([]string{"a"}, newMockNetworkReader())
+
+		_, err := reader.BulkMetrics()
+		Expect(t, err).To(Equal(metrics.ErrBulkUnsupported))
+	})
+}
+
+func TestAggregatorBulkMetrics(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it sums every file from each router", func(t *testing.T) {
+		var routers []metrics.Router
+		for i := 0; i < 2; i++ {
+			mockBulkRouter := newMockBulkRouter()
+			mockBulkRouter.BulkMetricsOutput.Metrics <- map[string]router.Metric{
+				"some-file": {WriteCount: 1, ErrCount: 2},
+			}
+			routers = append(routers, mockBulkRouter)
+		}
+
+		m, err := metrics.NewAggregator(routers).BulkMetrics()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, m["some-file"].WriteCount).To(Equal(uint64(2)))
+		Expect(t, m["some-file"].ErrCount).To(Equal(uint64(4)))
+	})
+
+	o.Spec("it returns an error when a router does not support bulk reads", func(t *testing.T) {
+		agg := metrics.NewAggregator([]metrics.Router{newMockRouter()})
+
+		_, err := agg.BulkMetrics()
+		Expect(t, err).To(Equal(metrics.ErrBulkUnsupported))
+	})
+}
+
+func TestDeltaBulkMetrics(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it returns the delta for every file", func(t *testing.T) {
+		mockBulkMetrics := newMockBulkMetrics()
+		close(mockBulkMetrics.BulkMetricsOutput.Err)
+		delta := metrics.NewDelta(10, mockBulkMetrics)
+
+		mockBulkMetrics.BulkMetricsOutput.Metrics <- map[string]router.Metric{
+			"some-file": {WriteCount: 5},
+		}
+		m, err := delta.BulkMetrics()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, m["some-file"].WriteCount).To(Equal(uint64(0)))
+
+		mockBulkMetrics.BulkMetricsOutput.Metrics <- map[string]router.Metric{
+			"some-file": {WriteCount: 8},
+		}
+		m, err = delta.BulkMetrics()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, m["some-file"].WriteCount).To(Equal(uint64(3)))
+	})
+
+	o.Spec("it returns an error when the metrics do not support bulk reads", func(t *testing.T) {
+		delta := metrics.NewDelta(10, newMockMetrics())
+
+		_, err := delta.BulkMetrics()
+		Expect(t, err).To(Equal(metrics.ErrBulkUnsupported))
+	})
+}
@@ -1,7 +1,9 @@
 package metrics
 
 import (
+	"container/list"
 	"sync"
+	"time"
 
 	"github.com/poy/petasos/router"
 )
@@ -10,59 +12,329 @@ type Metrics interface {
 	Metrics(file string) (metric router.Metric, err error)
 }
 
+// RouterMetrics is implemented by Metrics that can report each router's
+// metrics separately (e.g., Reader). The metrics are keyed by router
+// address.
+type RouterMetrics interface {
+	RouterMetrics(file string) (metrics map[string]router.Metric, missing []string, err error)
+}
+
+// BulkRouterMetrics is the bulk version of RouterMetrics. The metrics are
+// keyed by router address and then by file.
+type BulkRouterMetrics interface {
+	RouterBulkMetrics() (metrics map[string]map[string]router.Metric, missing []string, err error)
+}
+
+// Delta turns the cumulative metrics reported by routers into the change
+// over a window of time. Without a window, it reports the change since the
+// previous call for the file. With a window, the change is reported as is
+// until a full window of history is available, and is scaled to the window
+// after that.
+//
+// If the wrapped Metrics implements RouterMetrics, each router is tracked
+// separately. A router's counters going backwards (e.g., it restarted) are
+// treated as a reset, and the new value is counted as the change since the
+// reset. A router that is new, or that was missing, only counts the change
+// since it last reported, so the other routers' totals are never mistaken
+// for new writes. Otherwise, the same rules apply to the summed metrics.
+//
+// Delta tracks at most cacheSize files, evicting the least recently used.
 type Delta struct {
-	mu        sync.Mutex
 	metrics   Metrics
 	cacheSize int
-	data      map[string]router.Metric
+	conf      deltaConfig
+
+	mu    sync.Mutex
+	lru   *list.List
+	files map[string]*list.Element
+}
+
+type deltaConfig struct {
+	window time.Duration
+	now    func() time.Time
+}
+
+type DeltaOpts func(c *deltaConfig)
+
+type fileHistory struct {
+	file    string
+	routers map[string]*routerHistory
+	retired router.Metric
+	samples []sample
+}
+
+// routerHistory is the change a router has reported for a file since Delta
+// first saw it.
+type routerHistory struct {
+	last  router.Metric
+	total router.Metric
 }
 
-func NewDelta(cacheSize int, metrics Metrics) *Delta {
+type sample struct {
+	at     time.Time
+	metric router.Metric
+}
+
+func NewDelta(cacheSize int, metrics Metrics, opts ...DeltaOpts) *Delta {
+	conf := deltaConfig{
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
 	return &Delta{
 		metrics:   metrics,
 		cacheSize: cacheSize,
-		data:      make(map[string]router.Metric),
+		conf:      conf,
+		lru:       list.New(),
+		files:     make(map[string]*list.Element),
 	}
 }
 
 func (d *Delta) Metrics(file string) (metric router.Metric, err error) {
-	data := d.fetchData()
+	metric, _, err = d.PartialMetrics(file)
+	return metric, err
+}
 
-	current, err := d.metrics.Metrics(file)
+// PartialMetrics is Metrics along with the routers that did not answer,
+// if the wrapped Metrics implements RouterMetrics.
+func (d *Delta) PartialMetrics(file string) (metric router.Metric, missing []string, err error) {
+	current, missing, err := d.routerMetrics(file)
 	if err != nil {
-		return router.Metric{}, err
+		return router.Metric{}, nil, err
 	}
 
-	prev, ok := d.cas(data, file, current)
-	if !ok {
-		return router.Metric{}, nil
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.delta(file, current, missing, d.conf.now()), missing, nil
+}
+
+// routerMetrics returns the file's metrics from each router. If the wrapped
+// Metrics does not implement RouterMetrics, the sum is returned as a single
+// router.
+func (d *Delta) routerMetrics(file string) (metrics map[string]router.Metric, missing []string, err error) {
+	if r, ok := d.metrics.(RouterMetrics); ok {
+		return r.RouterMetrics(file)
 	}
 
-	return router.Metric{
-		WriteCount: current.WriteCount - prev.WriteCount,
-		ErrCount:   current.ErrCount - prev.ErrCount,
-	}, nil
+	metric, err := d.metrics.Metrics(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return map[string]router.Metric{"": metric}, nil, nil
+}
+
+// BulkMetrics returns the delta for every range file. The wrapped Metrics
+// must implement BulkMetrics or BulkRouterMetrics.
+func (d *Delta) BulkMetrics() (metrics map[string]router.Metric, err error) {
+	metrics, _, err = d.PartialBulkMetrics()
+	return metrics, err
 }
 
-func (d *Delta) fetchData() map[string]router.Metric {
+// PartialBulkMetrics is the bulk version of PartialMetrics.
+func (d *Delta) PartialBulkMetrics() (metrics map[string]router.Metric, missing []string, err error) {
+	current, missing, err := d.bulkRouterMetrics()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	if len(d.data) > d.cacheSize {
-		d.data = make(map[string]router.Metric)
+	now := d.conf.now()
+	metrics = make(map[string]router.Metric, len(current))
+	for file, m := range current {
+		metrics[file] = d.delta(file, m, missing, now)
+	}
+	return metrics, missing, nil
+}
+
+// bulkRouterMetrics returns every file's metrics from each router, keyed by
+// file and then by router address. A router that answered without a file
+// reports zero for it.
+func (d *Delta) bulkRouterMetrics() (metrics map[string]map[string]router.Metric, missing []string, err error) {
+	if r, ok := d.metrics.(BulkRouterMetrics); ok {
+		routers, missing, err := r.RouterBulkMetrics()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		metrics = make(map[string]map[string]router.Metric)
+		for _, files := range routers {
+			for file := range files {
+				metrics[file] = make(map[string]router.Metric, len(routers))
+			}
+		}
+
+		for addr, files := range routers {
+			for file := range metrics {
+				metrics[file][addr] = files[file]
+			}
+		}
+		return metrics, missing, nil
+	}
+
+	bulk, ok := d.metrics.(BulkMetrics)
+	if !ok {
+		return nil, nil, ErrBulkUnsupported
+	}
+
+	current, err := bulk.BulkMetrics()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return d.data
+	metrics = make(map[string]map[string]router.Metric, len(current))
+	for file, m := range current {
+		metrics[file] = map[string]router.Metric{"": m}
+	}
+	return metrics, nil, nil
 }
 
-func (d *Delta) cas(data map[string]router.Metric, file string, current router.Metric) (router.Metric, bool) {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+// delta records the current value and returns the change since the start
+// of the window (or the previous value when there is no window). It
+// returns an empty metric for files it has not seen before.
+func (d *Delta) delta(file string, current map[string]router.Metric, missing []string, now time.Time) router.Metric {
+	h, ok := d.history(file)
+	h.update(current, missing)
 
-	prev, ok := data[file]
+	adjusted := h.total()
 	if !ok {
-		data[file] = current
-		return router.Metric{}, false
+		h.samples = []sample{{at: now, metric: adjusted}}
+		return router.Metric{}
+	}
+
+	base := h.base(now, d.conf.window)
+	h.samples = append(h.samples, sample{at: now, metric: adjusted})
+
+	metric := sub(adjusted, base.metric)
+	if d.conf.window <= 0 {
+		return metric
+	}
+
+	// Scaling a change over a short time (e.g., two callers a moment
+	// apart) would turn a handful of writes into a huge rate. Until a full
+	// window has been seen, the change is reported as is instead.
+	elapsed := now.Sub(base.at)
+	if elapsed < d.conf.window {
+		return metric
+	}
+
+	return scale(metric, float64(d.conf.window)/float64(elapsed))
+}
+
+// history returns the file's history, marking it as recently used. If the
+// file is new, it is added and the least recently used file is evicted if
+// needed.
+func (d *Delta) history(file string) (*fileHistory, bool) {
+	if e, ok := d.files[file]; ok {
+		d.lru.MoveToFront(e)
+		return e.Value.(*fileHistory), true
+	}
+
+	h := &fileHistory{file: file}
+	d.files[file] = d.lru.PushFront(h)
+
+	for d.lru.Len() > d.cacheSize {
+		oldest := d.lru.Back()
+		d.lru.Remove(oldest)
+		delete(d.files, oldest.Value.(*fileHistory).file)
+	}
+
+	return h, false
+}
+
+// update records each router's current value. Routers that neither
+// answered nor are missing have been removed. Their totals are kept so the
+// file's total never goes backwards.
+func (h *fileHistory) update(current map[string]router.Metric, missing []string) {
+	if h.routers == nil {
+		h.routers = make(map[string]*routerHistory)
+	}
+
+	for addr, m := range current {
+		r, ok := h.routers[addr]
+		if !ok {
+			h.routers[addr] = &routerHistory{last: m}
+			continue
+		}
+
+		if isReset(r.last, m) {
+			r.total = add(r.total, m)
+		} else {
+			r.total = add(r.total, sub(m, r.last))
+		}
+		r.last = m
+	}
+
+	isMissing := make(map[string]bool, len(missing))
+	for _, addr := range missing {
+		isMissing[addr] = true
+	}
+
+	for addr, r := range h.routers {
+		if _, ok := current[addr]; ok || isMissing[addr] {
+			continue
+		}
+
+		h.retired = add(h.retired, r.total)
+		delete(h.routers, addr)
+	}
+}
+
+// total returns the change every router has reported since Delta first saw
+// the file.
+func (h *fileHistory) total() (total router.Metric) {
+	total = h.retired
+	for _, r := range h.routers {
+		total = add(total, r.total)
+	}
+	return total
+}
+
+// base returns the sample to measure from and drops samples that are no
+// longer needed. Without a window, it is the previous sample. Otherwise, it
+// is the newest sample at or before the start of the window, or the oldest
+// sample if none are that old.
+func (h *fileHistory) base(now time.Time, window time.Duration) sample {
+	if window <= 0 {
+		base := h.samples[len(h.samples)-1]
+		h.samples = h.samples[:0]
+		return base
+	}
+
+	start := now.Add(-window)
+	i := 0
+	for i+1 < len(h.samples) && !h.samples[i+1].at.After(start) {
+		i++
 	}
-	return prev, ok
+	h.samples = h.samples[i:]
+
+	return h.samples[0]
+}
+
+func isReset(prev, current router.Metric) bool {
+	return current.WriteCount < prev.WriteCount ||
+		current.ErrCount < prev.ErrCount ||
+		current.ByteCount < prev.ByteCount ||
+		current.Latency.Count() < prev.Latency.Count()
+}
+
+func sub(current, prev router.Metric) router.Metric {
+	return router.Metric{
+		WriteCount: current.WriteCount - prev.WriteCount,
+		ErrCount:   current.ErrCount - prev.ErrCount,
+		ByteCount:  current.ByteCount - prev.ByteCount,
+		Latency:    current.Latency.Sub(prev.Latency),
+	}
+}
+
+func scale(m router.Metric, factor float64) router.Metric {
+	m.WriteCount = uint64(float64(m.WriteCount)*factor + 0.5)
+	m.ErrCount = uint64(float64(m.ErrCount)*factor + 0.5)
+	m.ByteCount = uint64(float64(m.ByteCount)*factor + 0.5)
+	return m
 }
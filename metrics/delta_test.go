@@ -3,6 +3,7 @@ package metrics_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/poy/onpar"
 	. "github.com/poy/onpar/expect"
@@ -62,6 +63,7 @@ func TestRateCalc(t *testing.T) {
 			t.mockMetrics.MetricsOutput.Metric <- router.Metric{
 				WriteCount: 5,
 				ErrCount:   3,
+				ByteCount:  50,
 			}
 
 			m, _ := t.calc.Metrics("some-file")
@@ -69,10 +71,59 @@ func TestRateCalc(t *testing.T) {
 			t.mockMetrics.MetricsOutput.Metric <- router.Metric{
 				WriteCount: 7,
 				ErrCount:   5,
+				ByteCount:  70,
 			}
 			m, _ = t.calc.Metrics("some-file")
 			Expect(t, m.WriteCount).To(Equal(uint64(2)))
 			Expect(t, m.ErrCount).To(Equal(uint64(2)))
+			Expect(t, m.ByteCount).To(Equal(uint64(20)))
+		})
+
+		o.Spec("it returns the delta from the previous call", func(t TD) {
+			for _, count := range []uint64{5, 7, 10} {
+				t.mockMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: count}
+			}
+
+			t.calc.Metrics("some-file")
+			m, _ := t.calc.Metrics("some-file")
+			Expect(t, m.WriteCount).To(Equal(uint64(2)))
+
+			m, _ = t.calc.Metrics("some-file")
+			Expect(t, m.WriteCount).To(Equal(uint64(3)))
+		})
+
+		o.Spec("it treats counters going backwards as a reset", func(t TD) {
+			for _, count := range []uint64{10, 3, 5} {
+				t.mockMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: count}
+			}
+
+			t.calc.Metrics("some-file")
+			m, _ := t.calc.Metrics("some-file")
+			Expect(t, m.WriteCount).To(Equal(uint64(3)))
+
+			m, _ = t.calc.Metrics("some-file")
+			Expect(t, m.WriteCount).To(Equal(uint64(2)))
+		})
+
+		o.Spec("it evicts the least recently used file", func(t TD) {
+			t.mockMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: 5}
+			t.calc.Metrics("some-file")
+
+			for i := 0; i < 10; i++ {
+				t.mockMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: 5}
+				t.calc.Metrics(fmt.Sprintf("some-file-%d", i))
+
+				t.mockMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: 5}
+				t.calc.Metrics("some-file")
+			}
+
+			t.mockMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: 6}
+			m, _ := t.calc.Metrics("some-file")
+			Expect(t, m.WriteCount).To(Equal(uint64(1)))
+
+			t.mockMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: 6}
+			m, _ = t.calc.Metrics("some-file-0")
+			Expect(t, m.WriteCount).To(Equal(uint64(0)))
 		})
 
 		o.Spec("it uses the correct file", func(t TD) {
@@ -120,3 +171,172 @@ func TestRateCalc(t *testing.T) {
 	})
 
 }
+
+func TestRateCalcRouters(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it detects a router restarting", func(t *testing.T) {
+		calc := metrics.NewDelta(10, &stubRouterMetrics{results: []stubRouterResult{
+			{metrics: map[string]router.Metric{"a": {WriteCount: 100}, "b": {WriteCount: 100}}},
+			{metrics: map[string]router.Metric{"a": {WriteCount: 110}, "b": {WriteCount: 5}}},
+		}})
+
+		calc.Metrics("some-file")
+		m, err := calc.Metrics("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, m.WriteCount).To(Equal(uint64(15)))
+	})
+
+	o.Spec("it does not count a router's totals when it is missing or new", func(t *testing.T) {
+		calc := metrics.NewDelta(10, &stubRouterMetrics{results: []stubRouterResult{
+			{metrics: map[string]router.Metric{"a": {WriteCount: 100}, "b": {WriteCount: 100}}},
+			{metrics: map[string]router.Metric{"a": {WriteCount: 110}}, missing: []string{"b"}},
+			{metrics: map[string]router.Metric{"a": {WriteCount: 120}, "b": {WriteCount: 130}}},
+			{metrics: map[string]router.Metric{"a": {WriteCount: 130}, "b": {WriteCount: 140}, "c": {WriteCount: 1000}}},
+		}})
+
+		calc.Metrics("some-file")
+		for _, expected := range []uint64{10, 40, 20} {
+			m, _ := calc.Metrics("some-file")
+			Expect(t, m.WriteCount).To(Equal(expected))
+		}
+	})
+
+	o.Spec("it keeps the totals of removed routers", func(t *testing.T) {
+		calc := metrics.NewDelta(10, &stubRouterMetrics{results: []stubRouterResult{
+			{metrics: map[string]router.Metric{"a": {WriteCount: 100}, "b": {WriteCount: 100}}},
+			{metrics: map[string]router.Metric{"a": {WriteCount: 110}}},
+			{metrics: map[string]router.Metric{"a": {WriteCount: 120}}},
+		}})
+
+		calc.Metrics("some-file")
+		for _, expected := range []uint64{10, 10} {
+			m, _ := calc.Metrics("some-file")
+			Expect(t, m.WriteCount).To(Equal(expected))
+		}
+	})
+
+	o.Spec("it reports the missing routers", func(t *testing.T) {
+		calc := metrics.NewDelta(10, &stubRouterMetrics{results: []stubRouterResult{
+			{metrics: map[string]router.Metric{"a": {WriteCount: 100}}, missing: []string{"b"}},
+		}})
+
+		_, missing, err := calc.PartialMetrics("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, missing).To(Equal([]string{"b"}))
+	})
+
+	o.Spec("it tracks each router for every file", func(t *testing.T) {
+		calc := metrics.NewDelta(10, &stubRouterMetrics{bulk: []map[string]map[string]router.Metric{
+			{"a": {"some-file": {WriteCount: 100}}, "b": {"some-file": {WriteCount: 100}}},
+			{"a": {"some-file": {WriteCount: 110}}, "b": {}},
+			{"a": {"some-file": {WriteCount: 120}}, "b": {"some-file": {WriteCount: 3}}},
+		}})
+
+		calc.BulkMetrics()
+		for _, expected := range []uint64{10, 13} {
+			m, err := calc.BulkMetrics()
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, m["some-file"].WriteCount).To(Equal(expected))
+		}
+	})
+}
+
+type stubRouterResult struct {
+	metrics map[string]router.Metric
+	missing []string
+}
+
+type stubRouterMetrics struct {
+	results []stubRouterResult
+	bulk    []map[string]map[string]router.Metric
+}
+
+func (s *stubRouterMetrics) Metrics(file string) (router.Metric, error) {
+	return router.Metric{}, fmt.Errorf("not used")
+}
+
+func (s *stubRouterMetrics) RouterMetrics(file string) (map[string]router.Metric, []string, error) {
+	r := s.results[0]
+	s.results = s.results[1:]
+	return r.metrics, r.missing, nil
+}
+
+func (s *stubRouterMetrics) RouterBulkMetrics() (map[string]map[string]router.Metric, []string, error) {
+	r := s.bulk[0]
+	s.bulk = s.bulk[1:]
+	return r, nil, nil
+}
+
+func TestRateCalcWindow(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TDW {
+		mockMetrics := newMockMetrics()
+		close(mockMetrics.MetricsOutput.Err)
+
+		now := time.Unix(0, 0)
+		return TDW{
+			T:           t,
+			now:         &now,
+			mockMetrics: mockMetrics,
+			calc: metrics.NewDelta(10, mockMetrics,
+				metrics.WithWindow(10*time.Second),
+				metrics.WithClock(func() time.Time { return now }),
+			),
+		}
+	})
+
+	o.Spec("it scales the change to the window", func(t TDW) {
+		t.observe(0, 100)
+		t.observe(5*time.Second, 150)
+		m := t.observe(20*time.Second, 300)
+		Expect(t, m.WriteCount).To(Equal(uint64(100)))
+	})
+
+	o.Spec("it measures from the start of the window", func(t TDW) {
+		t.observe(0, 100)
+		t.observe(10*time.Second, 200)
+		t.observe(15*time.Second, 300)
+		m := t.observe(20*time.Second, 310)
+		Expect(t, m.WriteCount).To(Equal(uint64(110)))
+	})
+
+	o.Spec("it does not scale the change until a full window has been seen", func(t TDW) {
+		t.observe(0, 100)
+		m := t.observe(time.Millisecond, 103)
+		Expect(t, m.WriteCount).To(Equal(uint64(3)))
+
+		m = t.observe(5*time.Second, 150)
+		Expect(t, m.WriteCount).To(Equal(uint64(50)))
+
+		m = t.observe(10*time.Second, 210)
+		Expect(t, m.WriteCount).To(Equal(uint64(110)))
+	})
+
+	o.Spec("it is not affected by multiple callers", func(t TDW) {
+		t.observe(0, 100)
+		t.observe(10*time.Second, 200)
+		t.observe(10*time.Second, 200)
+		m := t.observe(10*time.Second, 200)
+		Expect(t, m.WriteCount).To(Equal(uint64(100)))
+	})
+}
+
+type TDW struct {
+	*testing.T
+	now         *time.Time
+	mockMetrics *mockMetrics
+	calc        *metrics.Delta
+}
+
+func (t TDW) observe(at time.Duration, count uint64) router.Metric {
+	*t.now = time.Unix(0, 0).Add(at)
+	t.mockMetrics.MetricsOutput.Metric <- router.Metric{WriteCount: count}
+	m, _ := t.calc.Metrics("some-file")
+	return m
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Discoverer returns the current set of router addresses.
+type Discoverer interface {
+	Addrs() (addrs []string, err error)
+}
+
+// StaticDiscoverer always returns the same addresses.
+type StaticDiscoverer []string
+
+func (s StaticDiscoverer) Addrs() (addrs []string, err error) {
+	return s, nil
+}
+
+// SRVResolver is implemented by *net.Resolver.
+type SRVResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (cname string, addrs []*net.SRV, err error)
+}
+
+// SRVDiscoverer looks up router addresses from a DNS SRV record. Results are
+// cached for the given TTL. If a lookup fails, the last known addresses are
+// returned.
+type SRVDiscoverer struct {
+	resolver             SRVResolver
+	service, proto, name string
+	ttl                  time.Duration
+
+	mu      sync.Mutex
+	addrs   []string
+	expires time.Time
+}
+
+func NewSRVDiscoverer(resolver SRVResolver, service, proto, name string, ttl time.Duration) *SRVDiscoverer {
+	return &SRVDiscoverer{
+		resolver: resolver,
+		service:  service,
+		proto:    proto,
+		name:     name,
+		ttl:      ttl,
+	}
+}
+
+func (d *SRVDiscoverer) Addrs() (addrs []string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.addrs != nil && time.Now().Before(d.expires) {
+		return d.addrs, nil
+	}
+
+	_, srvs, err := d.resolver.LookupSRV(context.Background(), d.service, d.proto, d.name)
+	if err != nil {
+		if d.addrs != nil {
+			return d.addrs, nil
+		}
+		return nil, err
+	}
+
+	addrs = []string{}
+	for _, srv := range srvs {
+		host := strings.TrimSuffix(srv.Target, ".")
+		addrs = append(addrs, net.JoinHostPort(host, fmt.Sprint(srv.Port)))
+	}
+	sort.Strings(addrs)
+
+	d.addrs = addrs
+	d.expires = time.Now().Add(d.ttl)
+
+	return addrs, nil
+}
+
+// FileDiscoverer reads router addresses from a file with one address per
+// line. Blank lines and lines starting with # are ignored. The file is
+// re-read whenever its modification time or size changes. If it can not be
+// read, the last known addresses are returned.
+type FileDiscoverer struct {
+	path string
+
+	mu      sync.Mutex
+	addrs   []string
+	modTime time.Time
+	size    int64
+}
+
+func NewFileDiscoverer(path string) *FileDiscoverer {
+	return &FileDiscoverer{
+		path: path,
+	}
+}
+
+func (d *FileDiscoverer) Addrs() (addrs []string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	addrs, err = d.read()
+	if err != nil {
+		if d.addrs != nil {
+			return d.addrs, nil
+		}
+		return nil, err
+	}
+
+	return addrs, nil
+}
+
+func (d *FileDiscoverer) read() (addrs []string, err error) {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.addrs != nil && info.ModTime().Equal(d.modTime) && info.Size() == d.size {
+		return d.addrs, nil
+	}
+
+	f, err := os.Open(d.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	addrs = []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addrs = append(addrs, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	d.addrs = addrs
+	d.modTime = info.ModTime()
+	d.size = info.Size()
+
+	return addrs, nil
+}
@@ -0,0 +1,293 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/metrics"
+	"github.com/poy/petasos/router"
+)
+
+func TestStaticDiscoverer(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it returns the given addresses", func(t *testing.T) {
+		addrs, err := metrics.StaticDiscoverer{"a", "b"}.Addrs()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, addrs).To(Equal([]string{"a", "b"}))
+	})
+}
+
+type TSRV struct {
+	*testing.T
+	resolver   *stubResolver
+	discoverer *metrics.SRVDiscoverer
+}
+
+func TestSRVDiscoverer(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TSRV {
+		resolver := &stubResolver{
+			srvs: []*net.SRV{
+				{Target: "b.example.com.", Port: 8080},
+				{Target: "a.example.com.", Port: 8081},
+			},
+		}
+
+		return TSRV{
+			T:          t,
+			resolver:   resolver,
+			discoverer: metrics.NewSRVDiscoverer(resolver, "metrics", "tcp", "routers.example.com", time.Hour),
+		}
+	})
+
+	o.Spec("it returns the targets of the SRV record", func(t TSRV) {
+		addrs, err := t.discoverer.Addrs()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, addrs).To(Equal([]string{"a.example.com:8081", "b.example.com:8080"}))
+		Expect(t, t.resolver.name).To(Equal("routers.example.com"))
+	})
+
+	o.Spec("it caches the results for the TTL", func(t TSRV) {
+		t.discoverer.Addrs()
+		t.discoverer.Addrs()
+
+		Expect(t, t.resolver.calls).To(Equal(1))
+	})
+
+	o.Spec("it returns the last known addresses when the lookup fails", func(t TSRV) {
+		d := metrics.NewSRVDiscoverer(t.resolver, "metrics", "tcp", "routers.example.com", 0)
+		d.Addrs()
+
+		t.resolver.err = fmt.Errorf("some-error")
+		addrs, err := d.Addrs()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, addrs).To(HaveLen(2))
+	})
+
+	o.Spec("it returns an error when the first lookup fails", func(t TSRV) {
+		t.resolver.err = fmt.Errorf("some-error")
+
+		_, err := t.discoverer.Addrs()
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
+
+type TF struct {
+	*testing.T
+	path       string
+	discoverer *metrics.FileDiscoverer
+}
+
+func TestFileDiscoverer(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TF {
+		dir, err := ioutil.TempDir("", "petasos")
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "routers")
+
+		if err := ioutil.WriteFile(path, []byte("a\n\n# comment\nb\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		return TF{
+			T:          t,
+			path:       path,
+			discoverer: metrics.NewFileDiscoverer(path),
+		}
+	})
+
+	o.AfterEach(func(t TF) {
+		os.RemoveAll(filepath.Dir(t.path))
+	})
+
+	o.Spec("it returns each address in the file", func(t TF) {
+		addrs, err := t.discoverer.Addrs()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, addrs).To(Equal([]string{"a", "b"}))
+	})
+
+	o.Spec("it picks up changes to the file", func(t TF) {
+		t.discoverer.Addrs()
+
+		if err := ioutil.WriteFile(t.path, []byte("a\nb\nc\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		addrs, err := t.discoverer.Addrs()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, addrs).To(Equal([]string{"a", "b", "c"}))
+	})
+
+	o.Spec("it returns the last known addresses when the file can not be read", func(t TF) {
+		t.discoverer.Addrs()
+
+		if err := os.Remove(t.path); err != nil {
+			t.Fatal(err)
+		}
+
+		addrs, err := t.discoverer.Addrs()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, addrs).To(Equal([]string{"a", "b"}))
+	})
+
+	o.Spec("it returns an error when the file does not exist", func(t TF) {
+		_, err := metrics.NewFileDiscoverer(t.path + "-missing").Addrs()
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
+
+func TestDiscoveringReader(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it reads from the currently discovered routers", func(t *testing.T) {
+		network := stubNetworkReader{
+			"a": {metric: router.Metric{WriteCount: 1}},
+			"b": {metric: router.Metric{WriteCount: 2}},
+		}
+		discoverer := &stubDiscoverer{addrs: []string{"a"}}
+		reader := metrics.NewDiscoveringReader(discoverer, network)
+
+		metric, err := reader.Metrics("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, metric.WriteCount).To(Equal(uint64(1)))
+
+		discoverer.addrs = []string{"a", "b"}
+		metric, err = reader.Metrics("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, metric.WriteCount).To(Equal(uint64(3)))
+	})
+
+	o.Spec("it returns an error when discovery fails", func(t *testing.T) {
+		discoverer := &stubDiscoverer{err: fmt.Errorf("some-error")}
+		reader := metrics.NewDiscoveringReader(discoverer, stubNetworkReader{})
+
+		_, err := reader.Metrics("some-file")
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
+
+func TestDiscoveringAggregator(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it aggregates the currently discovered routers", func(t *testing.T) {
+		discoverer := &stubDiscoverer{addrs: []string{"a", "b"}}
+		var dialed []string
+		agg := metrics.NewDiscoveringAggregator(discoverer, func(addr string) metrics.Router {
+			dialed = append(dialed, addr)
+			return stubRouter{WriteCount: 1}
+		}, metrics.WithAggregatorRefreshInterval(0))
+
+		Expect(t, agg.Metrics("some-file").WriteCount).To(Equal(uint64(2)))
+
+		discoverer.addrs = []string{"b", "c", "d"}
+		Expect(t, agg.Metrics("some-file").WriteCount).To(Equal(uint64(3)))
+		Expect(t, dialed).To(HaveLen(4))
+	})
+
+	o.Spec("it closes routers that are no longer discovered", func(t *testing.T) {
+		discoverer := &stubDiscoverer{addrs: []string{"a", "b"}}
+		var closed []string
+		agg := metrics.NewDiscoveringAggregator(discoverer, func(addr string) metrics.Router {
+			return closingRouter{addr: addr, closed: &closed}
+		}, metrics.WithAggregatorRefreshInterval(0))
+
+		agg.Metrics("some-file")
+		Expect(t, closed).To(HaveLen(0))
+
+		discoverer.addrs = []string{"b"}
+		agg.Metrics("some-file")
+		Expect(t, closed).To(Equal([]string{"a"}))
+	})
+
+	o.Spec("it only discovers the routers once per refresh interval", func(t *testing.T) {
+		discoverer := &stubDiscoverer{addrs: []string{"a", "b"}}
+		agg := metrics.NewDiscoveringAggregator(discoverer, func(addr string) metrics.Router {
+			return stubRouter{WriteCount: 1}
+		}, metrics.WithAggregatorRefreshInterval(time.Minute))
+
+		for i := 0; i < 5; i++ {
+			Expect(t, agg.Metrics("some-file").WriteCount).To(Equal(uint64(2)))
+		}
+		Expect(t, discoverer.calls).To(Equal(1))
+	})
+
+	o.Spec("it logs discovery failures to the given logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		discoverer := &stubDiscoverer{err: fmt.Errorf("some-error")}
+		agg := metrics.NewDiscoveringAggregator(discoverer, func(addr string) metrics.Router {
+			return stubRouter{}
+		}, metrics.WithAggregatorLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+
+		agg.Metrics("some-file")
+		Expect(t, buf.String()).To(ContainSubstring("some-error"))
+	})
+}
+
+type stubResolver struct {
+	srvs  []*net.SRV
+	err   error
+	name  string
+	calls int
+}
+
+func (r *stubResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	r.calls++
+	r.name = name
+	return "", r.srvs, r.err
+}
+
+type stubDiscoverer struct {
+	addrs []string
+	err   error
+	calls int
+}
+
+func (d *stubDiscoverer) Addrs() ([]string, error) {
+	d.calls++
+	return d.addrs, d.err
+}
+
+type stubRouter router.Metric
+
+func (r stubRouter) Metrics(file string) router.Metric {
+	return router.Metric(r)
+}
+
+type closingRouter struct {
+	addr   string
+	closed *[]string
+}
+
+func (r closingRouter) Metrics(file string) router.Metric {
+	return router.Metric{}
+}
+
+func (r closingRouter) Close() {
+	*r.closed = append(*r.closed, r.addr)
+}
@@ -0,0 +1,139 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/poy/petasos/metrics/grpc/metricspb"
+	"github.com/poy/petasos/router"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client reads metrics from routers serving a Server. It implements
+// metrics.NetworkReader and metrics.ContextNetworkReader. A connection is
+// kept open to each address until Close is called.
+type Client struct {
+	dialOpts []grpc.DialOption
+	timeout  time.Duration
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+type ClientOpts func(c *Client)
+
+// WithDialOptions sets the options used to connect to each router. It
+// defaults to an insecure connection.
+func WithDialOptions(opts ...grpc.DialOption) func(c *Client) {
+	return func(c *Client) {
+		c.dialOpts = opts
+	}
+}
+
+// WithTimeout sets the timeout for each request. It defaults to 5 seconds.
+func WithTimeout(timeout time.Duration) func(c *Client) {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+func NewClient(opts ...ClientOpts) *Client {
+	c := &Client{
+		dialOpts: []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		},
+		timeout: 5 * time.Second,
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// ReadMetrics requests the metrics for the given file from the router at
+// addr.
+func (c *Client) ReadMetrics(addr, file string) (metric router.Metric, err error) {
+	return c.ReadMetricsContext(context.Background(), addr, file)
+}
+
+// ReadMetricsContext is ReadMetrics with the request canceled when the
+// context is done.
+func (c *Client) ReadMetricsContext(ctx context.Context, addr, file string) (metric router.Metric, err error) {
+	client, err := c.client(addr)
+	if err != nil {
+		return router.Metric{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := client.Read(ctx, &metricspb.ReadRequest{File: file})
+	if err != nil {
+		return router.Metric{}, err
+	}
+
+	return fromProto(resp.GetMetric()), nil
+}
+
+// ReadAll requests the metrics for every range from the router at addr in a
+// single call.
+func (c *Client) ReadAll(addr string) (metrics map[string]router.Metric, err error) {
+	return c.ReadAllContext(context.Background(), addr)
+}
+
+// ReadAllContext is ReadAll with the request canceled when the context is
+// done.
+func (c *Client) ReadAllContext(ctx context.Context, addr string) (metrics map[string]router.Metric, err error) {
+	client, err := c.client(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := client.ReadAll(ctx, &metricspb.ReadAllRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics = make(map[string]router.Metric, len(resp.GetMetrics()))
+	for file, m := range resp.GetMetrics() {
+		metrics[file] = fromProto(m)
+	}
+
+	return metrics, nil
+}
+
+// Close closes every open connection.
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for addr, conn := range c.conns {
+		conn.Close()
+		delete(c.conns, addr)
+	}
+}
+
+func (c *Client) client(addr string) (metricspb.MetricsClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, ok := c.conns[addr]
+	if !ok {
+		var err error
+		conn, err = grpc.NewClient(addr, c.dialOpts...)
+		if err != nil {
+			return nil, err
+		}
+		c.conns[addr] = conn
+	}
+
+	return metricspb.NewMetricsClient(conn), nil
+}
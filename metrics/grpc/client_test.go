@@ -0,0 +1,99 @@
+package grpc_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/metrics"
+	metricsgrpc "github.com/poy/petasos/metrics/grpc"
+	"github.com/poy/petasos/metrics/grpc/metricspb"
+	"github.com/poy/petasos/router"
+	"google.golang.org/grpc"
+)
+
+type TC struct {
+	*testing.T
+
+	addr    string
+	counter *router.Counter
+	server  *grpc.Server
+	client  *metricsgrpc.Client
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TC {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counter := router.NewCounter()
+		server := grpc.NewServer()
+		metricspb.RegisterMetricsServer(server, metricsgrpc.NewServer(counter))
+		go server.Serve(lis)
+
+		return TC{
+			T:       t,
+			addr:    lis.Addr().String(),
+			counter: counter,
+			server:  server,
+			client:  metricsgrpc.NewClient(metricsgrpc.WithTimeout(time.Second)),
+		}
+	})
+
+	o.AfterEach(func(t TC) {
+		t.client.Close()
+		t.server.Stop()
+	})
+
+	o.Spec("it reads the metrics from the router", func(t TC) {
+		rn := router.RangeName{Low: 1, High: 2, Term: 3}
+		t.counter.IncSuccess(rn, 10)
+		t.counter.RecordLatency(rn, time.Millisecond)
+
+		metric, err := t.client.ReadMetrics(t.addr, buildRangeName(1, 2, 3))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, metric).To(Equal(t.counter.Metrics(rn)))
+	})
+
+	o.Spec("it reads every range from the router", func(t TC) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 10)
+		t.counter.IncSuccess(router.RangeName{Low: 3, High: 4, Term: 5}, 10)
+
+		m, err := t.client.ReadAll(t.addr)
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, m).To(HaveLen(2))
+		Expect(t, m[buildRangeName(3, 4, 5)].ByteCount).To(Equal(uint64(10)))
+	})
+
+	o.Spec("it works with metrics.Reader", func(t TC) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 10)
+
+		reader := metrics.NewReader([]string{t.addr, t.addr}, t.client)
+		metric, err := reader.Metrics(buildRangeName(1, 2, 3))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, metric.WriteCount).To(Equal(uint64(2)))
+	})
+
+	o.Spec("it works with metrics.Reader bulk reads", func(t TC) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 10)
+
+		reader := metrics.NewReader([]string{t.addr, t.addr}, t.client)
+		m, err := reader.BulkMetrics()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, m[buildRangeName(1, 2, 3)].WriteCount).To(Equal(uint64(2)))
+	})
+
+	o.Spec("it returns an error for a non-petasos file", func(t TC) {
+		_, err := t.client.ReadMetrics(t.addr, "some-file")
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
@@ -0,0 +1,30 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/poy/petasos/metrics/grpc/metricspb"
+	"github.com/poy/petasos/router"
+)
+
+func toProto(m router.Metric) *metricspb.Metric {
+	return &metricspb.Metric{
+		WriteCount: m.WriteCount,
+		ErrCount:   m.ErrCount,
+		ByteCount:  m.ByteCount,
+		Latency: &metricspb.Histogram{
+			Buckets:  m.Latency.Buckets[:],
+			MaxNanos: int64(m.Latency.Max),
+		},
+	}
+}
+
+func fromProto(m *metricspb.Metric) (metric router.Metric) {
+	metric.WriteCount = m.GetWriteCount()
+	metric.ErrCount = m.GetErrCount()
+	metric.ByteCount = m.GetByteCount()
+	copy(metric.Latency.Buckets[:], m.GetLatency().GetBuckets())
+	metric.Latency.Max = time.Duration(m.GetLatency().GetMaxNanos())
+
+	return metric
+}
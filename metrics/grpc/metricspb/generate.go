@@ -0,0 +1,4 @@
+// Package metricspb contains the generated gRPC service for range metrics.
+package metricspb
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. metrics.proto
@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/poy/petasos/metrics/grpc/metricspb"
+	"github.com/poy/petasos/router"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Counter is implemented by router.Counter.
+type Counter interface {
+	Metrics(rn router.RangeName) (metric router.Metric)
+	All() (metrics map[router.RangeName]router.Metric)
+}
+
+// Server serves a router's metrics over gRPC. Register it with
+// metricspb.RegisterMetricsServer.
+type Server struct {
+	metricspb.UnimplementedMetricsServer
+
+	counter Counter
+	codec   router.RangeNameCodec
+}
+
+type ServerOpts func(s *Server)
+
+// WithCodec sets the codec used to encode and decode file names. It
+// defaults to router.JSONCodec.
+func WithCodec(codec router.RangeNameCodec) func(s *Server) {
+	return func(s *Server) {
+		s.codec = codec
+	}
+}
+
+func NewServer(counter Counter, opts ...ServerOpts) *Server {
+	s := &Server{
+		counter: counter,
+		codec:   router.JSONCodec{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *Server) Read(ctx context.Context, req *metricspb.ReadRequest) (*metricspb.ReadResponse, error) {
+	rn, err := s.codec.Decode(req.GetFile())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid file %s: %s", req.GetFile(), err)
+	}
+
+	return &metricspb.ReadResponse{
+		Metric: toProto(s.counter.Metrics(rn)),
+	}, nil
+}
+
+func (s *Server) ReadAll(ctx context.Context, req *metricspb.ReadAllRequest) (*metricspb.ReadAllResponse, error) {
+	resp := &metricspb.ReadAllResponse{
+		Metrics: make(map[string]*metricspb.Metric),
+	}
+
+	for rn, m := range s.counter.All() {
+		resp.Metrics[s.codec.Encode(rn)] = toProto(m)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,77 @@
+package grpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	metricsgrpc "github.com/poy/petasos/metrics/grpc"
+	"github.com/poy/petasos/metrics/grpc/metricspb"
+	"github.com/poy/petasos/router"
+)
+
+type TS struct {
+	*testing.T
+
+	counter *router.Counter
+	server  *metricsgrpc.Server
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TS {
+		counter := router.NewCounter()
+
+		return TS{
+			T:       t,
+			counter: counter,
+			server:  metricsgrpc.NewServer(counter),
+		}
+	})
+
+	o.Spec("it returns the metrics for the file", func(t TS) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 10)
+
+		resp, err := t.server.Read(context.Background(), &metricspb.ReadRequest{
+			File: buildRangeName(1, 2, 3),
+		})
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, resp.GetMetric().GetWriteCount()).To(Equal(uint64(1)))
+		Expect(t, resp.GetMetric().GetByteCount()).To(Equal(uint64(10)))
+	})
+
+	o.Spec("it returns an error for a non-petasos file", func(t TS) {
+		_, err := t.server.Read(context.Background(), &metricspb.ReadRequest{
+			File: "some-file",
+		})
+		Expect(t, err == nil).To(BeFalse())
+	})
+
+	o.Spec("it returns the metrics for every file", func(t TS) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 10)
+		t.counter.IncFailure(router.RangeName{Low: 3, High: 4, Term: 5})
+
+		resp, err := t.server.ReadAll(context.Background(), &metricspb.ReadAllRequest{})
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, resp.GetMetrics()).To(HaveLen(2))
+		Expect(t, resp.GetMetrics()[buildRangeName(1, 2, 3)].GetWriteCount()).To(Equal(uint64(1)))
+		Expect(t, resp.GetMetrics()[buildRangeName(3, 4, 5)].GetErrCount()).To(Equal(uint64(1)))
+	})
+}
+
+func buildRangeName(low, high, term uint64) string {
+	rn := router.RangeName{
+		Low:  low,
+		High: high,
+		Term: term,
+	}
+
+	j, _ := json.Marshal(rn)
+	return string(j)
+}
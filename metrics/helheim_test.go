@@ -81,3 +81,72 @@ func (m *mockNetworkReader) ReadMetrics(addr, file string) (metric router.Metric
 	m.ReadMetricsInput.File <- file
 	return <-m.ReadMetricsOutput.Metric, <-m.ReadMetricsOutput.Err
 }
+
+type mockBulkNetworkReader struct {
+	*mockNetworkReader
+	ReadAllCalled chan bool
+	ReadAllInput  struct {
+		Addr chan string
+	}
+	ReadAllOutput struct {
+		Metrics chan map[string]router.Metric
+		Err     chan error
+	}
+}
+
+func newMockBulkNetworkReader() *mockBulkNetworkReader {
+	m := &mockBulkNetworkReader{}
+	m.mockNetworkReader = newMockNetworkReader()
+	m.ReadAllCalled = make(chan bool, 100)
+	m.ReadAllInput.Addr = make(chan string, 100)
+	m.ReadAllOutput.Metrics = make(chan map[string]router.Metric, 100)
+	m.ReadAllOutput.Err = make(chan error, 100)
+	return m
+}
+func (m *mockBulkNetworkReader) ReadAll(addr string) (metrics map[string]router.Metric, err error) {
+	m.ReadAllCalled <- true
+	m.ReadAllInput.Addr <- addr
+	return <-m.ReadAllOutput.Metrics, <-m.ReadAllOutput.Err
+}
+
+type mockBulkMetrics struct {
+	*mockMetrics
+	BulkMetricsCalled chan bool
+	BulkMetricsOutput struct {
+		Metrics chan map[string]router.Metric
+		Err     chan error
+	}
+}
+
+func newMockBulkMetrics() *mockBulkMetrics {
+	m := &mockBulkMetrics{}
+	m.mockMetrics = newMockMetrics()
+	m.BulkMetricsCalled = make(chan bool, 100)
+	m.BulkMetricsOutput.Metrics = make(chan map[string]router.Metric, 100)
+	m.BulkMetricsOutput.Err = make(chan error, 100)
+	return m
+}
+func (m *mockBulkMetrics) BulkMetrics() (metrics map[string]router.Metric, err error) {
+	m.BulkMetricsCalled <- true
+	return <-m.BulkMetricsOutput.Metrics, <-m.BulkMetricsOutput.Err
+}
+
+type mockBulkRouter struct {
+	*mockRouter
+	BulkMetricsCalled chan bool
+	BulkMetricsOutput struct {
+		Metrics chan map[string]router.Metric
+	}
+}
+
+func newMockBulkRouter() *mockBulkRouter {
+	m := &mockBulkRouter{}
+	m.mockRouter = newMockRouter()
+	m.BulkMetricsCalled = make(chan bool, 100)
+	m.BulkMetricsOutput.Metrics = make(chan map[string]router.Metric, 100)
+	return m
+}
+func (m *mockBulkRouter) BulkMetrics() (metrics map[string]router.Metric) {
+	m.BulkMetricsCalled <- true
+	return <-m.BulkMetricsOutput.Metrics
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/poy/petasos/router"
+)
+
+// Client reads metrics from routers serving a Handler. It implements
+// metrics.NetworkReader and metrics.ContextNetworkReader.
+type Client struct {
+	client *http.Client
+	path   string
+}
+
+type ClientOpts func(c *Client)
+
+// WithHTTPClient sets the HTTP client used for each request. It defaults to
+// http.DefaultClient.
+func WithHTTPClient(client *http.Client) func(c *Client) {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithPath sets the path the Handler is served on. It defaults to
+// "/metrics".
+func WithPath(path string) func(c *Client) {
+	return func(c *Client) {
+		c.path = path
+	}
+}
+
+func NewClient(opts ...ClientOpts) *Client {
+	c := &Client{
+		client: http.DefaultClient,
+		path:   "/metrics",
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// ReadMetrics requests the metrics for the given file from the router at
+// addr. An addr without a scheme is assumed to be http.
+func (c *Client) ReadMetrics(addr, file string) (metric router.Metric, err error) {
+	return c.ReadMetricsContext(context.Background(), addr, file)
+}
+
+// ReadMetricsContext is ReadMetrics with the request canceled when the
+// context is done.
+func (c *Client) ReadMetricsContext(ctx context.Context, addr, file string) (metric router.Metric, err error) {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return router.Metric{}, err
+	}
+	u.Path = c.path
+	u.RawQuery = url.Values{"file": []string{file}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return router.Metric{}, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return router.Metric{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return router.Metric{}, fmt.Errorf("unexpected status code from %s: %d", addr, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&metric); err != nil {
+		return router.Metric{}, err
+	}
+
+	return metric, nil
+}
@@ -0,0 +1,101 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/metrics"
+	metricshttp "github.com/poy/petasos/metrics/http"
+	"github.com/poy/petasos/router"
+)
+
+type TC struct {
+	*testing.T
+
+	counter *router.Counter
+	server  *httptest.Server
+	client  *metricshttp.Client
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TC {
+		counter := router.NewCounter()
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metricshttp.NewHandler(counter))
+
+		return TC{
+			T:       t,
+			counter: counter,
+			server:  httptest.NewServer(mux),
+			client:  metricshttp.NewClient(),
+		}
+	})
+
+	o.AfterEach(func(t TC) {
+		t.server.Close()
+	})
+
+	o.Spec("it reads the metrics from the router", func(t TC) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 10)
+
+		metric, err := t.client.ReadMetrics(t.server.URL, buildRangeName(1, 2, 3))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, metric.WriteCount).To(Equal(uint64(1)))
+		Expect(t, metric.ByteCount).To(Equal(uint64(10)))
+	})
+
+	o.Spec("it assumes http when addr does not have a scheme", func(t TC) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 10)
+
+		addr := strings.TrimPrefix(t.server.URL, "http://")
+		metric, err := t.client.ReadMetrics(addr, buildRangeName(1, 2, 3))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, metric.WriteCount).To(Equal(uint64(1)))
+	})
+
+	o.Spec("it works with metrics.Reader", func(t TC) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 10)
+
+		reader := metrics.NewReader([]string{t.server.URL, t.server.URL}, t.client)
+		metric, err := reader.Metrics(buildRangeName(1, 2, 3))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, metric.WriteCount).To(Equal(uint64(2)))
+	})
+
+	o.Spec("it returns an error for a non-200", func(t TC) {
+		_, err := t.client.ReadMetrics(t.server.URL, "some-file")
+		Expect(t, err == nil).To(BeFalse())
+	})
+
+	o.Spec("it cancels the request when the context is done", func(t TC) {
+		canceled := make(chan bool, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			canceled <- true
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := t.client.ReadMetricsContext(ctx, server.URL, buildRangeName(1, 2, 3))
+		Expect(t, err == nil).To(BeFalse())
+		Expect(t, canceled).To(Chain(Receive(ReceiveWait(time.Second)), Equal(true)))
+	})
+
+	o.Spec("it returns an error when the router is unreachable", func(t TC) {
+		_, err := t.client.ReadMetrics("http://127.0.0.1:1", buildRangeName(1, 2, 3))
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
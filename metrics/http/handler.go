@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/poy/petasos/router"
+)
+
+// Counter is implemented by router.Counter.
+type Counter interface {
+	Metrics(rn router.RangeName) (metric router.Metric)
+}
+
+// Handler serves a router's metrics for a single range file. The file is
+// given by the "file" query parameter.
+type Handler struct {
+	counter Counter
+	codec   router.RangeNameCodec
+}
+
+type HandlerOpts func(h *Handler)
+
+// WithCodec sets the codec used to decode the file parameter. It defaults
+// to router.JSONCodec.
+func WithCodec(codec router.RangeNameCodec) func(h *Handler) {
+	return func(h *Handler) {
+		h.codec = codec
+	}
+}
+
+func NewHandler(counter Counter, opts ...HandlerOpts) *Handler {
+	h := &Handler{
+		counter: counter,
+		codec:   router.JSONCodec{},
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	file := r.URL.Query().Get("file")
+	if file == "" {
+		http.Error(w, "missing file", http.StatusBadRequest)
+		return
+	}
+
+	rn, err := h.codec.Decode(file)
+	if err != nil {
+		http.Error(w, "invalid file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.counter.Metrics(rn))
+}
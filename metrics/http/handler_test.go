@@ -0,0 +1,90 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	metricshttp "github.com/poy/petasos/metrics/http"
+	"github.com/poy/petasos/router"
+)
+
+type TH struct {
+	*testing.T
+
+	counter  *router.Counter
+	recorder *httptest.ResponseRecorder
+	handler  *metricshttp.Handler
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TH {
+		counter := router.NewCounter()
+
+		return TH{
+			T:        t,
+			counter:  counter,
+			recorder: httptest.NewRecorder(),
+			handler:  metricshttp.NewHandler(counter),
+		}
+	})
+
+	o.Spec("it returns the metrics for the file", func(t TH) {
+		rn := router.RangeName{Low: 1, High: 2, Term: 3}
+		t.counter.IncSuccess(rn, 10)
+		t.counter.IncFailure(rn)
+
+		req := httptest.NewRequest(http.MethodGet, "/metrics?file="+url.QueryEscape(buildRangeName(1, 2, 3)), nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusOK))
+
+		var metric router.Metric
+		err := json.Unmarshal(t.recorder.Body.Bytes(), &metric)
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, metric.WriteCount).To(Equal(uint64(1)))
+		Expect(t, metric.ErrCount).To(Equal(uint64(1)))
+		Expect(t, metric.ByteCount).To(Equal(uint64(10)))
+	})
+
+	o.Spec("it returns a 400 for a missing file", func(t TH) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	o.Spec("it returns a 400 for a non-petasos file", func(t TH) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics?file=some-file", nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	o.Spec("it returns a 405 for anything but GET", func(t TH) {
+		req := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+}
+
+func buildRangeName(low, high, term uint64) string {
+	rn := router.RangeName{
+		Low:  low,
+		High: high,
+		Term: term,
+	}
+
+	j, _ := json.Marshal(rn)
+	return string(j)
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithQuorum sets how many routers must answer for Reader to succeed.
+// Discovering fewer routers than the quorum is an error. It defaults to
+// every router.
+func WithQuorum(quorum int) func(c *readerConfig) {
+	return func(c *readerConfig) {
+		c.quorum = quorum
+	}
+}
+
+// WithTimeout sets how long Reader waits for each router. A router that
+// does not answer in time is reported as missing and, if the NetworkReader
+// implements ContextNetworkReader, its read is canceled. It defaults to no
+// timeout.
+func WithTimeout(timeout time.Duration) func(c *readerConfig) {
+	return func(c *readerConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithWindow makes Delta report the change over the given window of time.
+// Until a full window of history is available, the change so far is
+// reported. It defaults to the change since the previous call.
+func WithWindow(window time.Duration) func(c *deltaConfig) {
+	return func(c *deltaConfig) {
+		c.window = window
+	}
+}
+
+// WithClock sets the clock Delta uses to timestamp each observation. It
+// defaults to time.Now.
+func WithClock(now func() time.Time) func(c *deltaConfig) {
+	return func(c *deltaConfig) {
+		c.now = now
+	}
+}
+
+// WithAggregatorRefreshInterval sets how often a discovering Aggregator
+// asks its Discoverer for the routers. It defaults to 5 seconds.
+func WithAggregatorRefreshInterval(interval time.Duration) func(c *aggregatorConfig) {
+	return func(c *aggregatorConfig) {
+		c.refresh = interval
+	}
+}
+
+// WithAggregatorLogger sets the logger a discovering Aggregator reports
+// discovery and close failures to. It defaults to slog.Default().
+func WithAggregatorLogger(logger *slog.Logger) func(c *aggregatorConfig) {
+	return func(c *aggregatorConfig) {
+		c.logger = logger
+	}
+}
@@ -1,32 +1,274 @@
 package metrics
 
-import "github.com/poy/petasos/router"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/poy/petasos/router"
+)
+
+// ErrNoRouters is returned when there are no routers to read from.
+var ErrNoRouters = errors.New("no routers to read metrics from")
 
 type NetworkReader interface {
 	ReadMetrics(addr, file string) (metric router.Metric, err error)
 }
 
+// ContextNetworkReader is implemented by NetworkReaders that can stop a read
+// when the context is done. Reader uses it so reads that exceed the timeout
+// are abandoned instead of left running.
+type ContextNetworkReader interface {
+	ReadMetricsContext(ctx context.Context, addr, file string) (metric router.Metric, err error)
+}
+
 type Reader struct {
-	network NetworkReader
-	addrs   []string
+	network    NetworkReader
+	discoverer Discoverer
+	conf       readerConfig
+}
+
+type readerConfig struct {
+	quorum  int
+	timeout time.Duration
 }
 
-func NewReader(addrs []string, network NetworkReader) *Reader {
+type ReaderOpts func(c *readerConfig)
+
+// NewReader returns a Reader that sums the metrics from each router. By
+// default every router must answer and there is no timeout.
+func NewReader(addrs []string, network NetworkReader, opts ...ReaderOpts) *Reader {
+	return NewDiscoveringReader(StaticDiscoverer(addrs), network, opts...)
+}
+
+// NewDiscoveringReader returns a Reader that asks the Discoverer for the
+// current routers on every read.
+func NewDiscoveringReader(discoverer Discoverer, network NetworkReader, opts ...ReaderOpts) *Reader {
+	var conf readerConfig
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
 	return &Reader{
-		addrs:   addrs,
-		network: network,
+		discoverer: discoverer,
+		network:    network,
+		conf:       conf,
+	}
+}
+
+// QuorumError is returned when fewer routers than the quorum answered or
+// were discovered.
+type QuorumError struct {
+	Quorum  int
+	Missing []string
+	Errs    []error
+}
+
+func (e *QuorumError) Error() string {
+	var errs []string
+	for _, err := range e.Errs {
+		errs = append(errs, err.Error())
 	}
+
+	return fmt.Sprintf("quorum of %d not met, missing %s: %s",
+		e.Quorum, strings.Join(e.Missing, ", "), strings.Join(errs, "; "))
 }
 
 func (r *Reader) Metrics(file string) (metric router.Metric, err error) {
-	for _, addr := range r.addrs {
+	metric, _, err = r.PartialMetrics(file)
+	return metric, err
+}
+
+// PartialMetrics returns the summed metrics for the file from every router
+// that answered along with the addresses of the routers that did not. It
+// only returns an error if the quorum was not met.
+func (r *Reader) PartialMetrics(file string) (metric router.Metric, missing []string, err error) {
+	results, missing, err := r.fanOut(r.readMetrics(file))
+	if err != nil {
+		return router.Metric{}, missing, err
+	}
+
+	for _, res := range results {
+		metric = add(metric, res.metric)
+	}
+	return metric, missing, nil
+}
+
+// RouterMetrics is PartialMetrics without summing. The metrics are keyed by
+// router address.
+func (r *Reader) RouterMetrics(file string) (metrics map[string]router.Metric, missing []string, err error) {
+	results, missing, err := r.fanOut(r.readMetrics(file))
+	if err != nil {
+		return nil, missing, err
+	}
+
+	metrics = make(map[string]router.Metric, len(results))
+	for _, res := range results {
+		metrics[res.addr] = res.metric
+	}
+	return metrics, missing, nil
+}
+
+// BulkMetrics returns the summed metrics for every range file with a single
+// call per router. The NetworkReader must implement BulkNetworkReader.
+func (r *Reader) BulkMetrics() (metrics map[string]router.Metric, err error) {
+	metrics, _, err = r.PartialBulkMetrics()
+	return metrics, err
+}
+
+// PartialBulkMetrics is the bulk version of PartialMetrics.
+func (r *Reader) PartialBulkMetrics() (metrics map[string]router.Metric, missing []string, err error) {
+	results, missing, err := r.fanOutBulk()
+	if err != nil {
+		return nil, missing, err
+	}
+
+	metrics = make(map[string]router.Metric)
+	for _, res := range results {
+		for file, metric := range res.metrics {
+			metrics[file] = add(metrics[file], metric)
+		}
+	}
+	return metrics, missing, nil
+}
+
+// RouterBulkMetrics is the bulk version of RouterMetrics. The metrics are
+// keyed by router address and then by file.
+func (r *Reader) RouterBulkMetrics() (metrics map[string]map[string]router.Metric, missing []string, err error) {
+	results, missing, err := r.fanOutBulk()
+	if err != nil {
+		return nil, missing, err
+	}
+
+	metrics = make(map[string]map[string]router.Metric, len(results))
+	for _, res := range results {
+		metrics[res.addr] = res.metrics
+	}
+	return metrics, missing, nil
+}
+
+type result struct {
+	addr    string
+	metric  router.Metric
+	metrics map[string]router.Metric
+	err     error
+}
+
+func (r *Reader) readMetrics(file string) func(ctx context.Context, addr string) result {
+	if network, ok := r.network.(ContextNetworkReader); ok {
+		return func(ctx context.Context, addr string) result {
+			m, err := network.ReadMetricsContext(ctx, addr, file)
+			return result{metric: m, err: err}
+		}
+	}
+
+	return func(ctx context.Context, addr string) result {
 		m, err := r.network.ReadMetrics(addr, file)
-		if err != nil {
-			return router.Metric{}, err
+		return result{metric: m, err: err}
+	}
+}
+
+// fanOutBulk is fanOut with every router reading all of its metrics.
+func (r *Reader) fanOutBulk() (results []result, missing []string, err error) {
+	bulk, ok := r.network.(BulkNetworkReader)
+	if !ok {
+		return nil, nil, ErrBulkUnsupported
+	}
+
+	if bulk, ok := bulk.(ContextBulkNetworkReader); ok {
+		return r.fanOut(func(ctx context.Context, addr string) result {
+			m, err := bulk.ReadAllContext(ctx, addr)
+			return result{metrics: m, err: err}
+		})
+	}
+
+	return r.fanOut(func(ctx context.Context, addr string) result {
+		m, err := bulk.ReadAll(addr)
+		return result{metrics: m, err: err}
+	})
+}
+
+// fanOut calls read for every router concurrently. Routers that return an
+// error or do not answer within the timeout are reported as missing. Fewer
+// routers than the quorum is an error.
+func (r *Reader) fanOut(read func(ctx context.Context, addr string) result) (results []result, missing []string, err error) {
+	addrs, err := r.discoverer.Addrs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(addrs) == 0 {
+		return nil, nil, ErrNoRouters
+	}
+
+	quorum := r.conf.quorum
+	if quorum <= 0 {
+		quorum = len(addrs)
+	}
+
+	if len(addrs) < quorum {
+		return nil, nil, &QuorumError{
+			Quorum: quorum,
+			Errs:   []error{fmt.Errorf("only %d routers discovered", len(addrs))},
+		}
+	}
+
+	c := make(chan result, len(addrs))
+	for _, addr := range addrs {
+		go func(addr string) {
+			c <- r.readWithTimeout(addr, read)
+		}(addr)
+	}
+
+	var errs []error
+	for range addrs {
+		res := <-c
+		if res.err != nil {
+			missing = append(missing, res.addr)
+			errs = append(errs, fmt.Errorf("%s: %s", res.addr, res.err))
+			continue
 		}
 
-		metric.WriteCount += m.WriteCount
-		metric.ErrCount += m.ErrCount
+		results = append(results, res)
+	}
+	sort.Strings(missing)
+
+	if len(results) < quorum {
+		return nil, missing, &QuorumError{
+			Quorum:  quorum,
+			Missing: missing,
+			Errs:    errs,
+		}
+	}
+
+	return results, missing, nil
+}
+
+func (r *Reader) readWithTimeout(addr string, read func(ctx context.Context, addr string) result) result {
+	ctx := context.Background()
+	if r.conf.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.conf.timeout)
+		defer cancel()
+	}
+
+	c := make(chan result, 1)
+	go func() {
+		res := read(ctx, addr)
+		res.addr = addr
+		c <- res
+	}()
+
+	select {
+	case res := <-c:
+		return res
+	case <-ctx.Done():
+		return result{
+			addr: addr,
+			err:  fmt.Errorf("timed out after %s", r.conf.timeout),
+		}
 	}
-	return metric, nil
 }
@@ -3,6 +3,7 @@
 package metrics_test
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -74,6 +75,7 @@ func TestMetrics(t *testing.T) {
 			testhelpers.AlwaysReturn(t.mockNetworkReader.ReadMetricsOutput.Metric, router.Metric{
 				WriteCount: 1,
 				ErrCount:   1,
+				ByteCount:  2,
 			})
 
 			metric, err := t.reader.Metrics("some-file")
@@ -81,6 +83,7 @@ func TestMetrics(t *testing.T) {
 
 			Expect(t, metric.WriteCount).To(Equal(uint64(3)))
 			Expect(t, metric.ErrCount).To(Equal(uint64(3)))
+			Expect(t, metric.ByteCount).To(Equal(uint64(6)))
 		})
 	})
 
@@ -99,6 +102,134 @@ func TestMetrics(t *testing.T) {
 	})
 }
 
+func TestMetricsQuorum(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TR {
+		network := stubNetworkReader{
+			"a": {metric: router.Metric{WriteCount: 1}},
+			"b": {metric: router.Metric{WriteCount: 1}},
+			"c": {err: fmt.Errorf("some-error")},
+			"d": {metric: router.Metric{WriteCount: 1}, delay: time.Second},
+		}
+
+		return TR{
+			T:     t,
+			addrs: []string{"a", "b", "c", "d"},
+			reader: metrics.NewReader([]string{"a", "b", "c", "d"}, network,
+				metrics.WithQuorum(2),
+				metrics.WithTimeout(50*time.Millisecond),
+			),
+		}
+	})
+
+	o.Spec("it sums the routers that answered", func(t TR) {
+		metric, err := t.reader.Metrics("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, metric.WriteCount).To(Equal(uint64(2)))
+	})
+
+	o.Spec("it reports the missing routers", func(t TR) {
+		_, missing, err := t.reader.PartialMetrics("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, missing).To(Equal([]string{"c", "d"}))
+	})
+
+	o.Spec("it reports each router that answered", func(t TR) {
+		routers, missing, err := t.reader.RouterMetrics("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, missing).To(Equal([]string{"c", "d"}))
+		Expect(t, routers).To(Equal(map[string]router.Metric{
+			"a": {WriteCount: 1},
+			"b": {WriteCount: 1},
+		}))
+	})
+
+	o.Spec("it returns an error when there are no routers", func(t TR) {
+		reader := metrics.NewReader(nil, stubNetworkReader{})
+
+		_, _, err := reader.PartialMetrics("some-file")
+		Expect(t, err).To(Equal(metrics.ErrNoRouters))
+	})
+
+	o.Spec("it returns an error when fewer routers than the quorum are discovered", func(t TR) {
+		reader := metrics.NewReader([]string{"a"}, stubNetworkReader{
+			"a": {metric: router.Metric{WriteCount: 1}},
+		}, metrics.WithQuorum(2))
+
+		_, _, err := reader.PartialMetrics("some-file")
+		Expect(t, err == nil).To(BeFalse())
+
+		_, ok := err.(*metrics.QuorumError)
+		Expect(t, ok).To(BeTrue())
+	})
+
+	o.Spec("it cancels the reads that time out", func(t TR) {
+		network := &contextNetworkReader{canceled: make(chan string, 4)}
+		reader := metrics.NewReader(t.addrs, network,
+			metrics.WithQuorum(2),
+			metrics.WithTimeout(50*time.Millisecond),
+		)
+
+		_, missing, err := reader.PartialMetrics("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, missing).To(Equal([]string{"d"}))
+		Expect(t, network.canceled).To(Chain(Receive(ReceiveWait(time.Second)), Equal("d")))
+	})
+
+	o.Spec("it returns an error when the quorum is not met", func(t TR) {
+		reader := metrics.NewReader(t.addrs, stubNetworkReader{
+			"a": {metric: router.Metric{WriteCount: 1}},
+			"b": {err: fmt.Errorf("some-error")},
+			"c": {err: fmt.Errorf("some-error")},
+			"d": {err: fmt.Errorf("some-error")},
+		}, metrics.WithQuorum(2))
+
+		_, missing, err := reader.PartialMetrics("some-file")
+		Expect(t, err == nil).To(BeFalse())
+		Expect(t, missing).To(Equal([]string{"b", "c", "d"}))
+
+		_, ok := err.(*metrics.QuorumError)
+		Expect(t, ok).To(BeTrue())
+	})
+}
+
+type stubResult struct {
+	metric router.Metric
+	err    error
+	delay  time.Duration
+}
+
+type stubNetworkReader map[string]stubResult
+
+func (s stubNetworkReader) ReadMetrics(addr, file string) (router.Metric, error) {
+	r := s[addr]
+	time.Sleep(r.delay)
+	return r.metric, r.err
+}
+
+// contextNetworkReader answers every address but d, which blocks until its
+// context is done.
+type contextNetworkReader struct {
+	canceled chan string
+}
+
+func (r *contextNetworkReader) ReadMetrics(addr, file string) (router.Metric, error) {
+	panic("ReadMetricsContext should be used")
+}
+
+func (r *contextNetworkReader) ReadMetricsContext(ctx context.Context, addr, file string) (router.Metric, error) {
+	if addr != "d" {
+		return router.Metric{WriteCount: 1}, nil
+	}
+
+	<-ctx.Done()
+	r.canceled <- addr
+	return router.Metric{}, ctx.Err()
+}
+
 func toSlice(c <-chan string, count int) []string {
 	var results []string
 	for i := 0; i < count; i++ {
@@ -0,0 +1,66 @@
+// Package migrate renames range files from one RangeNameCodec to another,
+// or to the name a codec encodes when given the same codec twice. Routers,
+// readers and maintainers should be stopped while it runs and restarted
+// with the new codec.
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/poy/petasos/router"
+)
+
+type FileSystem interface {
+	List() (file []string, err error)
+	Rename(from, to string) (err error)
+}
+
+// Rename is a planned or completed rename.
+type Rename struct {
+	From, To string
+}
+
+// Plan returns the renames needed to move every file that from can decode
+// to the name to encodes. Files from can not decode (e.g., already migrated
+// or non-petasos files) are skipped.
+func Plan(fs FileSystem, from, to router.RangeNameCodec) (renames []Rename, err error) {
+	list, err := fs.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range list {
+		rn, err := from.Decode(file)
+		if err != nil {
+			continue
+		}
+
+		name := to.Encode(rn)
+		if name == file {
+			continue
+		}
+
+		renames = append(renames, Rename{From: file, To: name})
+	}
+
+	return renames, nil
+}
+
+// Run renames every file that from can decode. It stops at the first
+// failed rename and returns the renames that completed. As migrated files
+// are skipped, it is safe to run again.
+func Run(fs FileSystem, from, to router.RangeNameCodec) (done []Rename, err error) {
+	renames, err := Plan(fs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range renames {
+		if err := fs.Rename(r.From, r.To); err != nil {
+			return done, fmt.Errorf("failed to rename %s to %s: %s", r.From, r.To, err)
+		}
+		done = append(done, r)
+	}
+
+	return done, nil
+}
@@ -0,0 +1,134 @@
+package migrate_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/migrate"
+	"github.com/poy/petasos/router"
+)
+
+type TM struct {
+	*testing.T
+
+	fs *stubFileSystem
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TM {
+		return TM{
+			T: t,
+			fs: &stubFileSystem{
+				files: map[string]bool{
+					router.JSONCodec{}.Encode(router.RangeName{Low: 0, High: 10, Term: 1}): true,
+					router.HexCodec{}.Encode(router.RangeName{Low: 11, High: 20, Term: 2}): true,
+					"some-file": true,
+				},
+			},
+		}
+	})
+
+	o.Spec("it renames files from the old codec", func(t TM) {
+		done, err := migrate.Run(t.fs, router.JSONCodec{}, router.HexCodec{})
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, done).To(HaveLen(1))
+
+		Expect(t, t.fs.list()).To(Equal([]string{
+			router.HexCodec{}.Encode(router.RangeName{Low: 0, High: 10, Term: 1}),
+			router.HexCodec{}.Encode(router.RangeName{Low: 11, High: 20, Term: 2}),
+			"some-file",
+		}))
+	})
+
+	o.Spec("it does nothing when run again", func(t TM) {
+		_, err := migrate.Run(t.fs, router.JSONCodec{}, router.HexCodec{})
+		Expect(t, err == nil).To(BeTrue())
+
+		done, err := migrate.Run(t.fs, router.JSONCodec{}, router.HexCodec{})
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, done).To(HaveLen(0))
+	})
+
+	o.Spec("it plans without renaming", func(t TM) {
+		renames, err := migrate.Plan(t.fs, router.JSONCodec{}, router.PathCodec{})
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, renames).To(Equal([]migrate.Rename{{
+			From: router.JSONCodec{}.Encode(router.RangeName{Low: 0, High: 10, Term: 1}),
+			To:   "petasos_0_10_1_0",
+		}}))
+		Expect(t, t.fs.renamed).To(HaveLen(0))
+	})
+
+	o.Spec("it renames names the codec would not encode to the encoded name", func(t TM) {
+		t.fs.files = map[string]bool{
+			`{"High":10,"Low":0,"Term":1,"Rand":5}`: true,
+		}
+
+		done, err := migrate.Run(t.fs, router.JSONCodec{}, router.JSONCodec{})
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, done).To(HaveLen(1))
+		Expect(t, t.fs.list()).To(Equal([]string{
+			router.JSONCodec{}.Encode(router.RangeName{Low: 0, High: 10, Term: 1, Rand: 5}),
+		}))
+	})
+
+	o.Spec("it stops at the first failed rename", func(t TM) {
+		t.fs.renameErr = fmt.Errorf("some-error")
+
+		done, err := migrate.Run(t.fs, router.JSONCodec{}, router.HexCodec{})
+		Expect(t, err == nil).To(BeFalse())
+		Expect(t, done).To(HaveLen(0))
+	})
+
+	o.Spec("it returns an error when listing fails", func(t TM) {
+		t.fs.listErr = fmt.Errorf("some-error")
+
+		_, err := migrate.Run(t.fs, router.JSONCodec{}, router.HexCodec{})
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
+
+type stubFileSystem struct {
+	files     map[string]bool
+	renamed   []string
+	listErr   error
+	renameErr error
+}
+
+func (s *stubFileSystem) List() ([]string, error) {
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+
+	return s.list(), nil
+}
+
+func (s *stubFileSystem) Rename(from, to string) error {
+	if s.renameErr != nil {
+		return s.renameErr
+	}
+
+	delete(s.files, from)
+	s.files[to] = true
+	s.renamed = append(s.renamed, from)
+
+	return nil
+}
+
+func (s *stubFileSystem) list() []string {
+	var files []string
+	for f := range s.files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	return files
+}
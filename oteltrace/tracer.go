@@ -0,0 +1,57 @@
+// Package oteltrace adapts an OpenTelemetry tracer to router.Tracer so
+// writes, reads and maintainer actions can be traced.
+package oteltrace
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/poy/petasos/router"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer is a router.Tracer backed by an OpenTelemetry tracer. Ranges are
+// recorded as the petasos.range.low, petasos.range.high and
+// petasos.range.term attributes. uint64s are recorded as strings as
+// OpenTelemetry only has signed integers.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+func New(tracer trace.Tracer) *Tracer {
+	return &Tracer{
+		tracer: tracer,
+	}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, router.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetRange(rn router.RangeName) {
+	s.span.SetAttributes(
+		attribute.String("petasos.range.low", strconv.FormatUint(rn.Low, 10)),
+		attribute.String("petasos.range.high", strconv.FormatUint(rn.High, 10)),
+		attribute.String("petasos.range.term", strconv.FormatUint(rn.Term, 10)),
+	)
+}
+
+func (s otelSpan) SetHash(hash uint64) {
+	s.span.SetAttributes(attribute.String("petasos.hash", strconv.FormatUint(hash, 10)))
+}
+
+func (s otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s otelSpan) End() {
+	s.span.End()
+}
@@ -0,0 +1,80 @@
+package oteltrace_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/oteltrace"
+	"github.com/poy/petasos/router"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type TT struct {
+	*testing.T
+
+	recorder *tracetest.SpanRecorder
+	tracer   *oteltrace.Tracer
+}
+
+func TestTracer(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TT {
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		return TT{
+			T:        t,
+			recorder: recorder,
+			tracer:   oteltrace.New(provider.Tracer("petasos")),
+		}
+	})
+
+	o.Spec("it records range attributes", func(t TT) {
+		_, span := t.tracer.Start(context.Background(), "some-span")
+		span.SetRange(router.RangeName{Low: 1, High: 18446744073709551615, Term: 3})
+		span.SetHash(99)
+		span.End()
+
+		spans := t.recorder.Ended()
+		Expect(t, spans).To(HaveLen(1))
+		Expect(t, spans[0].Name()).To(Equal("some-span"))
+		Expect(t, spans[0].Attributes()).To(Equal([]attribute.KeyValue{
+			attribute.String("petasos.range.low", "1"),
+			attribute.String("petasos.range.high", "18446744073709551615"),
+			attribute.String("petasos.range.term", "3"),
+			attribute.String("petasos.hash", "99"),
+		}))
+	})
+
+	o.Spec("it records errors", func(t TT) {
+		_, span := t.tracer.Start(context.Background(), "some-span")
+		span.RecordError(errors.New("some-error"))
+		span.End()
+
+		spans := t.recorder.Ended()
+		Expect(t, spans).To(HaveLen(1))
+		Expect(t, spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(t, spans[0].Events()).To(HaveLen(1))
+	})
+
+	o.Spec("it parents child spans", func(t TT) {
+		ctx, parent := t.tracer.Start(context.Background(), "parent")
+		_, child := t.tracer.Start(ctx, "child")
+		child.End()
+		parent.End()
+
+		spans := t.recorder.Ended()
+		Expect(t, spans).To(HaveLen(2))
+		Expect(t, spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+	})
+}
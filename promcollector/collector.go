@@ -0,0 +1,188 @@
+// Package promcollector exposes petasos range metrics, maintainer actions
+// and reader progress as Prometheus metrics.
+package promcollector
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/poy/petasos/maintainer"
+	"github.com/poy/petasos/router"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Counter is implemented by router.Counter.
+type Counter interface {
+	All() (metrics map[router.RangeName]router.Metric)
+}
+
+// Collector is a prometheus.Collector. It reports the Counter's metrics
+// for each range and implements maintainer.Observer and
+// reader.ProgressRecorder. Every metric is labeled with the range's low,
+// high and term.
+type Collector struct {
+	counter Counter
+
+	writes  *prometheus.Desc
+	errs    *prometheus.Desc
+	bytes   *prometheus.Desc
+	latency *prometheus.Desc
+
+	seeds    *prometheus.CounterVec
+	splits   *prometheus.CounterVec
+	combines *prometheus.CounterVec
+	gapFills *prometheus.CounterVec
+	failures *prometheus.CounterVec
+
+	reads     *prometheus.CounterVec
+	readIndex *prometheus.GaugeVec
+}
+
+var labels = []string{"low", "high", "term"}
+
+// New returns a Collector. counter may be nil when the process does not
+// route writes (e.g., a maintainer).
+func New(counter Counter) *Collector {
+	return &Collector{
+		counter: counter,
+
+		writes: prometheus.NewDesc(
+			"petasos_range_writes_total",
+			"Successful writes routed to the range.",
+			labels, nil,
+		),
+		errs: prometheus.NewDesc(
+			"petasos_range_errors_total",
+			"Failed writes routed to the range.",
+			labels, nil,
+		),
+		bytes: prometheus.NewDesc(
+			"petasos_range_bytes_total",
+			"Bytes successfully written to the range.",
+			labels, nil,
+		),
+		latency: prometheus.NewDesc(
+			"petasos_range_write_latency_seconds",
+			"Write latency for the range by quantile (1 is the max).",
+			append(labels, "quantile"), nil,
+		),
+
+		seeds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "petasos_balancer_seeds_total",
+			Help: "Ranges created by the balancer when seeding.",
+		}, labels),
+		splits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "petasos_balancer_splits_total",
+			Help: "Ranges split by the balancer, labeled by the range that was split.",
+		}, labels),
+		combines: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "petasos_balancer_combines_total",
+			Help: "Ranges created by the balancer combining two ranges, labeled by the new range.",
+		}, labels),
+		gapFills: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "petasos_filler_gap_fills_total",
+			Help: "Ranges created by the filler to fill a gap.",
+		}, labels),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "petasos_maintainer_action_failures_total",
+			Help: "Ranges the maintainers failed to create, labeled by the action.",
+		}, append([]string{"action"}, labels...)),
+
+		reads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "petasos_reader_reads_total",
+			Help: "Packets read from the range.",
+		}, labels),
+		readIndex: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "petasos_reader_index",
+			Help: "Index of the last packet read from the range.",
+		}, labels),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.writes
+	ch <- c.errs
+	ch <- c.bytes
+	ch <- c.latency
+
+	c.seeds.Describe(ch)
+	c.splits.Describe(ch)
+	c.combines.Describe(ch)
+	c.gapFills.Describe(ch)
+	c.failures.Describe(ch)
+	c.reads.Describe(ch)
+	c.readIndex.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	if c.counter != nil {
+		for rn, m := range c.counter.All() {
+			l := rangeLabels(rn)
+			ch <- prometheus.MustNewConstMetric(c.writes, prometheus.CounterValue, float64(m.WriteCount), l...)
+			ch <- prometheus.MustNewConstMetric(c.errs, prometheus.CounterValue, float64(m.ErrCount), l...)
+			ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.CounterValue, float64(m.ByteCount), l...)
+
+			if m.Latency.Count() == 0 {
+				continue
+			}
+
+			for _, q := range []struct {
+				quantile string
+				latency  time.Duration
+			}{
+				{"0.5", m.Latency.P50()},
+				{"0.99", m.Latency.P99()},
+				{"1", m.Latency.Max},
+			} {
+				ch <- prometheus.MustNewConstMetric(c.latency, prometheus.GaugeValue,
+					q.latency.Seconds(), append(l, q.quantile)...)
+			}
+		}
+	}
+
+	c.seeds.Collect(ch)
+	c.splits.Collect(ch)
+	c.combines.Collect(ch)
+	c.gapFills.Collect(ch)
+	c.failures.Collect(ch)
+	c.reads.Collect(ch)
+	c.readIndex.Collect(ch)
+}
+
+func (c *Collector) Observe(event maintainer.Event) {
+	switch e := event.(type) {
+	case maintainer.RangeSeeded:
+		c.seeds.WithLabelValues(rangeLabels(e.Range)...).Inc()
+	case maintainer.RangeSplit:
+		c.splits.WithLabelValues(rangeLabels(e.Parent)...).Inc()
+	case maintainer.RangesCombined:
+		c.combines.WithLabelValues(rangeLabels(e.Combined)...).Inc()
+	case maintainer.GapFilled:
+		c.gapFills.WithLabelValues(rangeLabels(e.Gap)...).Inc()
+	case maintainer.ActionFailed:
+		l := append([]string{string(e.Action)}, rangeLabels(e.Range)...)
+		c.failures.WithLabelValues(l...).Inc()
+	}
+}
+
+func (c *Collector) RecordRead(rn router.RangeName, index uint64) {
+	l := rangeLabels(rn)
+	c.reads.WithLabelValues(l...).Inc()
+	c.readIndex.WithLabelValues(l...).Set(float64(index))
+}
+
+// RecordDone deletes the reader metrics for a retired range so they do not
+// grow with every range ever read.
+func (c *Collector) RecordDone(rn router.RangeName) {
+	l := rangeLabels(rn)
+	c.reads.DeleteLabelValues(l...)
+	c.readIndex.DeleteLabelValues(l...)
+}
+
+func rangeLabels(rn router.RangeName) []string {
+	return []string{
+		strconv.FormatUint(rn.Low, 10),
+		strconv.FormatUint(rn.High, 10),
+		strconv.FormatUint(rn.Term, 10),
+	}
+}
@@ -0,0 +1,137 @@
+package promcollector_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/maintainer"
+	"github.com/poy/petasos/promcollector"
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/router"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var (
+	_ maintainer.Observer     = &promcollector.Collector{}
+	_ reader.ProgressRecorder = &promcollector.Collector{}
+)
+
+type TC struct {
+	*testing.T
+
+	counter   *router.Counter
+	collector *promcollector.Collector
+}
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TC {
+		counter := router.NewCounter()
+
+		return TC{
+			T:         t,
+			counter:   counter,
+			collector: promcollector.New(counter),
+		}
+	})
+
+	o.Spec("it exposes the counter metrics for each range", func(t TC) {
+		rn := router.RangeName{Low: 1, High: 2, Term: 3}
+		t.counter.IncSuccess(rn, 10)
+		t.counter.IncSuccess(rn, 10)
+		t.counter.IncFailure(rn)
+		t.counter.RecordLatency(rn, time.Millisecond)
+
+		err := testutil.CollectAndCompare(t.collector, strings.NewReader(`
+# HELP petasos_range_bytes_total Bytes successfully written to the range.
+# TYPE petasos_range_bytes_total counter
+petasos_range_bytes_total{high="2",low="1",term="3"} 20
+# HELP petasos_range_errors_total Failed writes routed to the range.
+# TYPE petasos_range_errors_total counter
+petasos_range_errors_total{high="2",low="1",term="3"} 1
+# HELP petasos_range_writes_total Successful writes routed to the range.
+# TYPE petasos_range_writes_total counter
+petasos_range_writes_total{high="2",low="1",term="3"} 2
+`), "petasos_range_writes_total", "petasos_range_errors_total", "petasos_range_bytes_total")
+		Expect(t, err == nil).To(BeTrue())
+	})
+
+	o.Spec("it exposes the latency quantiles for each range", func(t TC) {
+		rn := router.RangeName{Low: 1, High: 2, Term: 3}
+		t.counter.RecordLatency(rn, time.Millisecond)
+
+		count := testutil.CollectAndCount(t.collector, "petasos_range_write_latency_seconds")
+		Expect(t, count).To(Equal(3))
+	})
+
+	o.Spec("it counts maintainer actions", func(t TC) {
+		rn := router.RangeName{Low: 1, High: 2, Term: 3}
+		t.collector.Observe(maintainer.RangeSeeded{Range: rn})
+		t.collector.Observe(maintainer.RangeSplit{Parent: rn})
+		t.collector.Observe(maintainer.RangeSplit{Parent: rn})
+		t.collector.Observe(maintainer.RangesCombined{Combined: rn})
+		t.collector.Observe(maintainer.GapFilled{Gap: rn})
+		t.collector.Observe(maintainer.ActionFailed{Action: maintainer.ActionSplit, Range: rn})
+
+		err := testutil.CollectAndCompare(t.collector, strings.NewReader(`
+# HELP petasos_balancer_splits_total Ranges split by the balancer, labeled by the range that was split.
+# TYPE petasos_balancer_splits_total counter
+petasos_balancer_splits_total{high="2",low="1",term="3"} 2
+`), "petasos_balancer_splits_total")
+		Expect(t, err == nil).To(BeTrue())
+
+		Expect(t, testutil.CollectAndCount(t.collector,
+			"petasos_balancer_seeds_total",
+			"petasos_balancer_combines_total",
+			"petasos_filler_gap_fills_total",
+			"petasos_maintainer_action_failures_total",
+		)).To(Equal(4))
+	})
+
+	o.Spec("it exposes reader progress", func(t TC) {
+		rn := router.RangeName{Low: 1, High: 2, Term: 3}
+		t.collector.RecordRead(rn, 7)
+		t.collector.RecordRead(rn, 8)
+
+		err := testutil.CollectAndCompare(t.collector, strings.NewReader(`
+# HELP petasos_reader_index Index of the last packet read from the range.
+# TYPE petasos_reader_index gauge
+petasos_reader_index{high="2",low="1",term="3"} 8
+# HELP petasos_reader_reads_total Packets read from the range.
+# TYPE petasos_reader_reads_total counter
+petasos_reader_reads_total{high="2",low="1",term="3"} 2
+`), "petasos_reader_index", "petasos_reader_reads_total")
+		Expect(t, err == nil).To(BeTrue())
+	})
+
+	o.Spec("it deletes reader progress for retired ranges", func(t TC) {
+		rn := router.RangeName{Low: 1, High: 2, Term: 3}
+		t.collector.RecordRead(rn, 7)
+		t.collector.RecordRead(router.RangeName{Low: 1, High: 2, Term: 4}, 1)
+		t.collector.RecordDone(rn)
+
+		err := testutil.CollectAndCompare(t.collector, strings.NewReader(`
+# HELP petasos_reader_index Index of the last packet read from the range.
+# TYPE petasos_reader_index gauge
+petasos_reader_index{high="2",low="1",term="4"} 1
+# HELP petasos_reader_reads_total Packets read from the range.
+# TYPE petasos_reader_reads_total counter
+petasos_reader_reads_total{high="2",low="1",term="4"} 1
+`), "petasos_reader_index", "petasos_reader_reads_total")
+		Expect(t, err == nil).To(BeTrue())
+	})
+
+	o.Spec("it works without a counter", func(t TC) {
+		c := promcollector.New(nil)
+		c.Observe(maintainer.RangeSeeded{})
+
+		Expect(t, testutil.CollectAndCount(c)).To(Equal(1))
+	})
+}
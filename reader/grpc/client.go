@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"context"
+	"io"
+
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/reader/grpc/readerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client reads from a server serving a Server.
+type Client struct {
+	dialOpts []grpc.DialOption
+
+	conn   *grpc.ClientConn
+	client readerpb.ReaderClient
+}
+
+type ClientOpts func(c *Client)
+
+// WithDialOptions sets the options used to connect to the server. It
+// defaults to an insecure connection.
+func WithDialOptions(opts ...grpc.DialOption) func(c *Client) {
+	return func(c *Client) {
+		c.dialOpts = opts
+	}
+}
+
+func NewClient(addr string, opts ...ClientOpts) (*Client, error) {
+	c := &Client{
+		dialOpts: []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	conn, err := grpc.NewClient(addr, c.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+	c.client = readerpb.NewReaderClient(conn)
+
+	return c, nil
+}
+
+// ReadRange streams every range covering a hash from low to high (see
+// reader.RouteReader.ReadRange). With follow, the stream stays open for new
+// data until it is closed. Each file with a Position is read from after its
+// index; give the Stream's Positions to resume it.
+func (c *Client) ReadRange(ctx context.Context, low, high uint64, follow bool, start ...reader.Position) (*Stream, error) {
+	req := &readerpb.ReadRequest{
+		Low:    low,
+		High:   high,
+		Start:  toProto(start),
+		Follow: follow,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.client.Read(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &Stream{stream: stream, cancel: cancel, positions: start}, nil
+}
+
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+// Stream is a reader.ResumableReader over a Read stream. Read returns
+// io.EOF once the server has sent everything.
+type Stream struct {
+	stream grpc.ServerStreamingClient[readerpb.DataPacket]
+	cancel func()
+
+	positions []reader.Position
+}
+
+func (s *Stream) Read() (data reader.DataPacket, err error) {
+	p, err := s.stream.Recv()
+	if err == io.EOF {
+		return reader.DataPacket{}, io.EOF
+	}
+
+	if err != nil {
+		return reader.DataPacket{}, err
+	}
+
+	s.positions = fromProto(p.GetPositions())
+
+	return reader.DataPacket{
+		Payload:  p.GetPayload(),
+		Filename: p.GetFilename(),
+		Index:    p.GetIndex(),
+	}, nil
+}
+
+// Positions returns where to resume the read: the positions sent with the
+// last packet, or the ones the stream was started from before any packet.
+func (s *Stream) Positions() []reader.Position {
+	return s.positions
+}
+
+// Close ends the stream.
+func (s *Stream) Close() {
+	s.cancel()
+}
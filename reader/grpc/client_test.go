@@ -0,0 +1,145 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/reader"
+	readergrpc "github.com/poy/petasos/reader/grpc"
+	"github.com/poy/petasos/reader/grpc/readerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type TC struct {
+	*testing.T
+
+	rangeReader *stubRangeReader
+	server      *grpc.Server
+	client      *readergrpc.Client
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TC {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rangeReader := &stubRangeReader{
+			reader: &stubReader{},
+		}
+		server := grpc.NewServer()
+		readerpb.RegisterReaderServer(server, readergrpc.NewServer(rangeReader, readergrpc.WithPollInterval(time.Millisecond)))
+		go server.Serve(lis)
+
+		client, err := readergrpc.NewClient(lis.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return TC{
+			T:           t,
+			rangeReader: rangeReader,
+			server:      server,
+			client:      client,
+		}
+	})
+
+	o.AfterEach(func(t TC) {
+		t.client.Close()
+		t.server.Stop()
+	})
+
+	o.Spec("it streams each packet and then ends", func(t TC) {
+		t.rangeReader.reader.add(
+			reader.DataPacket{Payload: []byte("a"), Filename: "some-file", Index: 0},
+			reader.DataPacket{Payload: []byte("b"), Filename: "some-file", Index: 1},
+		)
+
+		s, err := t.client.ReadRange(context.Background(), 1, 2, false, reader.Position{Filename: "some-file", Index: 3})
+		Expect(t, err == nil).To(BeTrue())
+		defer s.Close()
+
+		for i, payload := range []string{"a", "b"} {
+			data, err := s.Read()
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, data).To(Equal(reader.DataPacket{Payload: []byte(payload), Filename: "some-file", Index: uint64(i)}))
+		}
+
+		_, err = s.Read()
+		Expect(t, err).To(Equal(io.EOF))
+
+		low, high, start := t.rangeReader.request()
+		Expect(t, low).To(Equal(uint64(1)))
+		Expect(t, high).To(Equal(uint64(2)))
+		Expect(t, start).To(Equal([]reader.Position{{Filename: "some-file", Index: 3}}))
+	})
+
+	o.Spec("it resumes from the positions sent with the last packet", func(t TC) {
+		t.rangeReader.reader.positions = []reader.Position{
+			{Filename: "other-file", Index: 0},
+			{Filename: "some-file", Index: 4},
+		}
+		t.rangeReader.reader.add(reader.DataPacket{Payload: []byte("a"), Filename: "other-file", Index: 0})
+
+		start := []reader.Position{{Filename: "some-file", Index: 4}}
+		s, err := t.client.ReadRange(context.Background(), 1, 1, false, start...)
+		Expect(t, err == nil).To(BeTrue())
+		defer s.Close()
+
+		Expect(t, s.Positions()).To(Equal(start))
+
+		_, err = s.Read()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, s.Positions()).To(Equal(t.rangeReader.reader.positions))
+
+		resumed, err := t.client.ReadRange(context.Background(), 1, 1, false, s.Positions()...)
+		Expect(t, err == nil).To(BeTrue())
+		defer resumed.Close()
+
+		_, err = resumed.Read()
+		Expect(t, err).To(Equal(io.EOF))
+
+		_, _, resumedStart := t.rangeReader.request()
+		Expect(t, resumedStart).To(Equal(t.rangeReader.reader.positions))
+	})
+
+	o.Spec("it follows new packets", func(t TC) {
+		s, err := t.client.ReadRange(context.Background(), 1, 1, true)
+		Expect(t, err == nil).To(BeTrue())
+		defer s.Close()
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			t.rangeReader.reader.add(reader.DataPacket{Payload: []byte("a")})
+		}()
+
+		data, err := s.Read()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, data.Payload).To(Equal([]byte("a")))
+	})
+
+	o.Spec("it returns Unavailable when the read fails", func(t TC) {
+		t.rangeReader.reader.err = fmt.Errorf("some-error")
+
+		s, err := t.client.ReadRange(context.Background(), 1, 1, false)
+		Expect(t, err == nil).To(BeTrue())
+		defer s.Close()
+
+		_, err = s.Read()
+		Expect(t, status.Code(err)).To(Equal(codes.Unavailable))
+	})
+}
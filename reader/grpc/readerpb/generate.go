@@ -0,0 +1,4 @@
+// Package readerpb contains the generated gRPC service for reads.
+package readerpb
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. reader.proto
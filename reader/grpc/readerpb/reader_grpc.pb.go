@@ -0,0 +1,134 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: reader.proto
+
+package readerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Reader_Read_FullMethodName = "/petasos.reader.Reader/Read"
+)
+
+// ReaderClient is the client API for Reader service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Reader streams the data written to a span of hashes.
+type ReaderClient interface {
+	// Read streams the packets of every range covering a hash from low to
+	// high, oldest term first. Without follow, the stream ends once every
+	// range has been read.
+	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DataPacket], error)
+}
+
+type readerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReaderClient(cc grpc.ClientConnInterface) ReaderClient {
+	return &readerClient{cc}
+}
+
+func (c *readerClient) Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DataPacket], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Reader_ServiceDesc.Streams[0], Reader_Read_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReadRequest, DataPacket]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Reader_ReadClient = grpc.ServerStreamingClient[DataPacket]
+
+// ReaderServer is the server API for Reader service.
+// All implementations must embed UnimplementedReaderServer
+// for forward compatibility.
+//
+// Reader streams the data written to a span of hashes.
+type ReaderServer interface {
+	// Read streams the packets of every range covering a hash from low to
+	// high, oldest term first. Without follow, the stream ends once every
+	// range has been read.
+	Read(*ReadRequest, grpc.ServerStreamingServer[DataPacket]) error
+	mustEmbedUnimplementedReaderServer()
+}
+
+// UnimplementedReaderServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReaderServer struct{}
+
+func (UnimplementedReaderServer) Read(*ReadRequest, grpc.ServerStreamingServer[DataPacket]) error {
+	return status.Error(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedReaderServer) mustEmbedUnimplementedReaderServer() {}
+func (UnimplementedReaderServer) testEmbeddedByValue()                {}
+
+// UnsafeReaderServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReaderServer will
+// result in compilation errors.
+type UnsafeReaderServer interface {
+	mustEmbedUnimplementedReaderServer()
+}
+
+func RegisterReaderServer(s grpc.ServiceRegistrar, srv ReaderServer) {
+	// If the following call panics, it indicates UnimplementedReaderServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Reader_ServiceDesc, srv)
+}
+
+func _Reader_Read_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReaderServer).Read(m, &grpc.GenericServerStream[ReadRequest, DataPacket]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Reader_ReadServer = grpc.ServerStreamingServer[DataPacket]
+
+// Reader_ServiceDesc is the grpc.ServiceDesc for Reader service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Reader_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "petasos.reader.Reader",
+	HandlerType: (*ReaderServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Read",
+			Handler:       _Reader_Read_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "reader.proto",
+}
@@ -0,0 +1,122 @@
+// Package grpc streams the data read by a reader.RouteReader over gRPC.
+package grpc
+
+import (
+	"io"
+	"time"
+
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/reader/grpc/readerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RangeReader is implemented by reader.RouteReader.
+type RangeReader interface {
+	ReadRange(low, high uint64, start ...reader.Position) reader.ResumableReader
+}
+
+// Server streams the packets read with a RangeReader. Register it with
+// readerpb.RegisterReaderServer. Each packet carries the Positions of the
+// reader so that a client can resume the read from it. A following stream
+// that has caught up checks for new data at the poll interval, backing off
+// to the max poll interval while there is none.
+type Server struct {
+	readerpb.UnimplementedReaderServer
+
+	reader      RangeReader
+	interval    time.Duration
+	maxInterval time.Duration
+}
+
+type ServerOpts func(s *Server)
+
+// WithPollInterval sets how often a following stream checks for new data.
+// It defaults to 250ms.
+func WithPollInterval(interval time.Duration) func(s *Server) {
+	return func(s *Server) {
+		s.interval = interval
+	}
+}
+
+// WithMaxPollInterval sets how far a following stream backs off while
+// there is no new data. It defaults to 5 seconds.
+func WithMaxPollInterval(interval time.Duration) func(s *Server) {
+	return func(s *Server) {
+		s.maxInterval = interval
+	}
+}
+
+func NewServer(reader RangeReader, opts ...ServerOpts) *Server {
+	s := &Server{
+		reader:      reader,
+		interval:    250 * time.Millisecond,
+		maxInterval: 5 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *Server) Read(req *readerpb.ReadRequest, stream grpc.ServerStreamingServer[readerpb.DataPacket]) error {
+	if req.GetLow() > req.GetHigh() {
+		return status.Errorf(codes.InvalidArgument, "low %d is above high %d", req.GetLow(), req.GetHigh())
+	}
+
+	r := s.reader.ReadRange(req.GetLow(), req.GetHigh(), fromProto(req.GetStart())...)
+	defer r.Close()
+
+	ctx := stream.Context()
+	wait := s.interval
+	for {
+		data, err := r.Read()
+		if err == io.EOF {
+			if !req.GetFollow() {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			wait = min(2*wait, max(s.maxInterval, s.interval))
+			continue
+		}
+		wait = s.interval
+
+		if err != nil {
+			return status.Errorf(codes.Unavailable, "failed to read: %s", err)
+		}
+
+		err = stream.Send(&readerpb.DataPacket{
+			Payload:   data.Payload,
+			Filename:  data.Filename,
+			Index:     data.Index,
+			Positions: toProto(r.Positions()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func toProto(positions []reader.Position) []*readerpb.Position {
+	ps := make([]*readerpb.Position, 0, len(positions))
+	for _, p := range positions {
+		ps = append(ps, &readerpb.Position{Filename: p.Filename, Index: p.Index})
+	}
+	return ps
+}
+
+func fromProto(positions []*readerpb.Position) []reader.Position {
+	ps := make([]reader.Position, 0, len(positions))
+	for _, p := range positions {
+		ps = append(ps, reader.Position{Filename: p.GetFilename(), Index: p.GetIndex()})
+	}
+	return ps
+}
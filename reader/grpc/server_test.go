@@ -0,0 +1,95 @@
+package grpc_test
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/reader"
+	readergrpc "github.com/poy/petasos/reader/grpc"
+	"github.com/poy/petasos/reader/grpc/readerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it rejects a low above high", func(t *testing.T) {
+		server := readergrpc.NewServer(&stubRangeReader{})
+
+		err := server.Read(&readerpb.ReadRequest{Low: 10, High: 9}, nil)
+		Expect(t, status.Code(err)).To(Equal(codes.InvalidArgument))
+	})
+}
+
+type stubRangeReader struct {
+	mu    sync.Mutex
+	low   uint64
+	high  uint64
+	start []reader.Position
+
+	reader *stubReader
+}
+
+func (s *stubRangeReader) ReadRange(low, high uint64, start ...reader.Position) reader.ResumableReader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.low, s.high, s.start = low, high, start
+	return s.reader
+}
+
+func (s *stubRangeReader) request() (low, high uint64, start []reader.Position) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.low, s.high, s.start
+}
+
+// stubReader returns its packets and then io.EOF until more are added.
+// Its Positions are the given positions.
+type stubReader struct {
+	mu        sync.Mutex
+	packets   []reader.DataPacket
+	err       error
+	positions []reader.Position
+}
+
+func (s *stubReader) add(packets ...reader.DataPacket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.packets = append(s.packets, packets...)
+}
+
+func (s *stubReader) Read() (reader.DataPacket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.packets) == 0 {
+		if s.err != nil {
+			return reader.DataPacket{}, s.err
+		}
+
+		return reader.DataPacket{}, io.EOF
+	}
+
+	p := s.packets[0]
+	s.packets = s.packets[1:]
+	return p, nil
+}
+
+func (s *stubReader) Positions() []reader.Position {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.positions
+}
+
+func (s *stubReader) Close() {}
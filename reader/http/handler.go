@@ -0,0 +1,225 @@
+// Package http streams the data read by a reader.RouteReader as server-sent
+// events.
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/poy/petasos/reader"
+)
+
+// RangeReader is implemented by reader.RouteReader.
+type RangeReader interface {
+	ReadRange(low, high uint64, start ...reader.Position) reader.ResumableReader
+}
+
+// Handler streams the packets read with a RangeReader for each GET. The
+// hashes are given by the "hash" query parameter, or "low" and "high" for a
+// span. Each "position" parameter resumes a file and is written as
+// <index>:<filename>. With "follow=true" the stream stays open for new data.
+//
+// Each packet is sent as a "packet" event with the JSON encoded
+// reader.DataPacket as the data. Its ID is a cursor holding the Positions
+// of the reader, encoded as "position" query parameters, so that a
+// Last-Event-ID resumes each file and not just the last one. The Positions
+// of retired ranges are left out once a newer range has been read, so the
+// cursor only grows with the ranges being read. Without follow, an "eof"
+// event is sent once everything has been read. A failed read is sent as an
+// "error" event.
+//
+// A following stream that has caught up checks for new data (listing the
+// ranges each time) at the poll interval, backing off to the max poll
+// interval while there is none.
+type Handler struct {
+	reader      RangeReader
+	interval    time.Duration
+	maxInterval time.Duration
+}
+
+type HandlerOpts func(h *Handler)
+
+// WithPollInterval sets how often a following stream checks for new data.
+// It defaults to 250ms.
+func WithPollInterval(interval time.Duration) func(h *Handler) {
+	return func(h *Handler) {
+		h.interval = interval
+	}
+}
+
+// WithMaxPollInterval sets how far a following stream backs off while
+// there is no new data. It defaults to 5 seconds.
+func WithMaxPollInterval(interval time.Duration) func(h *Handler) {
+	return func(h *Handler) {
+		h.maxInterval = interval
+	}
+}
+
+func NewHandler(reader RangeReader, opts ...HandlerOpts) *Handler {
+	h := &Handler{
+		reader:      reader,
+		interval:    250 * time.Millisecond,
+		maxInterval: 5 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	low, high, err := parseHashes(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start, err := parseStart(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	follow := r.URL.Query().Get("follow") == "true"
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	rr := h.reader.ReadRange(low, high, start...)
+	defer rr.Close()
+
+	wait := h.interval
+	for {
+		data, err := rr.Read()
+		if err == io.EOF {
+			if !follow {
+				fmt.Fprint(w, "event: eof\ndata:\n\n")
+				flusher.Flush()
+				return
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(wait):
+			}
+			wait = min(2*wait, max(h.maxInterval, h.interval))
+			continue
+		}
+		wait = h.interval
+
+		if err != nil {
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", strings.ReplaceAll(err.Error(), "\n", " "))
+			flusher.Flush()
+			return
+		}
+
+		packet, err := json.Marshal(data)
+		if err != nil {
+			return
+		}
+
+		if _, err := fmt.Fprintf(w, "event: packet\nid: %s\ndata: %s\n\n", encodeCursor(rr.Positions()), packet); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func parseHashes(r *http.Request) (low, high uint64, err error) {
+	q := r.URL.Query()
+	if hash := q.Get("hash"); hash != "" {
+		low, err = strconv.ParseUint(hash, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid hash: %s", hash)
+		}
+
+		return low, low, nil
+	}
+
+	if q.Get("low") == "" || q.Get("high") == "" {
+		return 0, 0, fmt.Errorf("missing hash or low and high")
+	}
+
+	low, err = strconv.ParseUint(q.Get("low"), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid low: %s", q.Get("low"))
+	}
+
+	high, err = strconv.ParseUint(q.Get("high"), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid high: %s", q.Get("high"))
+	}
+
+	if low > high {
+		return 0, 0, fmt.Errorf("low %d is above high %d", low, high)
+	}
+
+	return low, high, nil
+}
+
+// parseStart returns the positions from the query and then from the
+// Last-Event-ID cursor. A file in both is resumed from the cursor.
+func parseStart(r *http.Request) (start []reader.Position, err error) {
+	positions := r.URL.Query()["position"]
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		q, err := url.ParseQuery(id)
+		if err != nil || len(q["position"]) == 0 {
+			return nil, fmt.Errorf("invalid Last-Event-ID: %s", id)
+		}
+		positions = append(positions, q["position"]...)
+	}
+
+	seen := make(map[string]int)
+	for _, p := range positions {
+		index, file, ok := strings.Cut(p, ":")
+		if !ok || file == "" {
+			return nil, fmt.Errorf("invalid position: %s", p)
+		}
+
+		i, err := strconv.ParseUint(index, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid position: %s", p)
+		}
+
+		if j, ok := seen[file]; ok {
+			start[j].Index = i
+			continue
+		}
+
+		seen[file] = len(start)
+		start = append(start, reader.Position{Filename: file, Index: i})
+	}
+
+	return start, nil
+}
+
+// encodeCursor writes the positions as "position" query parameters.
+func encodeCursor(positions []reader.Position) string {
+	q := make(url.Values)
+	for _, p := range positions {
+		q.Add("position", fmt.Sprintf("%d:%s", p.Index, p.Filename))
+	}
+	return q.Encode()
+}
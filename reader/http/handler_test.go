@@ -0,0 +1,301 @@
+package http_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/reader"
+	readerhttp "github.com/poy/petasos/reader/http"
+)
+
+type TH struct {
+	*testing.T
+
+	rangeReader *stubRangeReader
+	recorder    *httptest.ResponseRecorder
+	handler     *readerhttp.Handler
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TH {
+		rangeReader := &stubRangeReader{
+			reader: &stubReader{},
+		}
+
+		return TH{
+			T:           t,
+			rangeReader: rangeReader,
+			recorder:    httptest.NewRecorder(),
+			handler:     readerhttp.NewHandler(rangeReader, readerhttp.WithPollInterval(time.Millisecond)),
+		}
+	})
+
+	o.Spec("it streams each packet as an event and then ends", func(t TH) {
+		t.rangeReader.reader.add(
+			reader.DataPacket{Payload: []byte("a"), Filename: "some-file", Index: 0},
+			reader.DataPacket{Payload: []byte("b"), Filename: "some-file", Index: 1},
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/read?hash=5", nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusOK))
+		Expect(t, t.recorder.Header().Get("Content-Type")).To(Equal("text/event-stream"))
+		Expect(t, t.recorder.Body.String()).To(Equal(
+			"event: packet\nid: position=0%3Asome-file\ndata: {\"Payload\":\"YQ==\",\"Filename\":\"some-file\",\"Index\":0}\n\n" +
+				"event: packet\nid: position=1%3Asome-file\ndata: {\"Payload\":\"Yg==\",\"Filename\":\"some-file\",\"Index\":1}\n\n" +
+				"event: eof\ndata:\n\n",
+		))
+
+		low, high, _ := t.rangeReader.request()
+		Expect(t, low).To(Equal(uint64(5)))
+		Expect(t, high).To(Equal(uint64(5)))
+	})
+
+	o.Spec("it reads a span of hashes from the given positions", func(t TH) {
+		req := httptest.NewRequest(http.MethodGet, "/read?low=1&high=2&position=3:some/file", nil)
+		req.Header.Set("Last-Event-ID", "position=7%3Aother-file")
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusOK))
+
+		low, high, start := t.rangeReader.request()
+		Expect(t, low).To(Equal(uint64(1)))
+		Expect(t, high).To(Equal(uint64(2)))
+		Expect(t, start).To(Equal([]reader.Position{
+			{Filename: "some/file", Index: 3},
+			{Filename: "other-file", Index: 7},
+		}))
+	})
+
+	o.Spec("it resumes every file read before reconnecting", func(t TH) {
+		t.rangeReader.reader.add(
+			reader.DataPacket{Payload: []byte("a"), Filename: "some-file", Index: 4},
+			reader.DataPacket{Payload: []byte("b"), Filename: "other-file", Index: 0},
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/read?hash=5&position=3:some-file&position=9:old-file", nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		var lastID string
+		for _, line := range strings.Split(t.recorder.Body.String(), "\n") {
+			if id, ok := strings.CutPrefix(line, "id: "); ok {
+				lastID = id
+			}
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/read?hash=5", nil)
+		req.Header.Set("Last-Event-ID", lastID)
+		t.handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		_, _, start := t.rangeReader.request()
+		Expect(t, start).To(Equal([]reader.Position{
+			{Filename: "old-file", Index: 9},
+			{Filename: "other-file", Index: 0},
+			{Filename: "some-file", Index: 4},
+		}))
+	})
+
+	o.Spec("it leaves the files the reader no longer needs out of the cursor", func(t TH) {
+		t.rangeReader.positions = []reader.Position{{Filename: "new-file", Index: 0}}
+		t.rangeReader.reader.add(
+			reader.DataPacket{Payload: []byte("a"), Filename: "new-file", Index: 0},
+		)
+
+		req := httptest.NewRequest(http.MethodGet, "/read?hash=5&position=9:old-file", nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Body.String()).To(ContainSubstring("id: position=0%3Anew-file\n"))
+	})
+
+	o.Spec("it resumes a file from the cursor over the query", func(t TH) {
+		req := httptest.NewRequest(http.MethodGet, "/read?hash=5&position=3:some-file", nil)
+		req.Header.Set("Last-Event-ID", "position=7%3Asome-file")
+		t.handler.ServeHTTP(t.recorder, req)
+
+		_, _, start := t.rangeReader.request()
+		Expect(t, start).To(Equal([]reader.Position{
+			{Filename: "some-file", Index: 7},
+		}))
+	})
+
+	o.Spec("it sends an error event when the read fails", func(t TH) {
+		t.rangeReader.reader.err = fmt.Errorf("some-error")
+
+		req := httptest.NewRequest(http.MethodGet, "/read?hash=5", nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Body.String()).To(Equal("event: error\ndata: some-error\n\n"))
+	})
+
+	o.Spec("it returns a 400 for invalid hashes", func(t TH) {
+		for _, query := range []string{"", "hash=x", "low=1", "low=2&high=1", "hash=1&position=x"} {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/read?"+query, nil)
+			t.handler.ServeHTTP(recorder, req)
+
+			Expect(t, recorder.Code).To(Equal(http.StatusBadRequest))
+		}
+	})
+
+	o.Spec("it returns a 405 for anything but a GET", func(t TH) {
+		req := httptest.NewRequest(http.MethodPost, "/read?hash=5", nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	o.Spec("it follows new packets", func(t TH) {
+		server := httptest.NewServer(t.handler)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/read?hash=5&follow=true")
+		Expect(t, err == nil).To(BeTrue())
+		defer resp.Body.Close()
+
+		t.rangeReader.reader.add(reader.DataPacket{Payload: []byte("a"), Filename: "some-file"})
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, line).To(Equal("event: packet\n"))
+	})
+
+	o.Spec("it backs off while there is no new data", func(t TH) {
+		handler := readerhttp.NewHandler(t.rangeReader,
+			readerhttp.WithPollInterval(time.Millisecond),
+			readerhttp.WithMaxPollInterval(20*time.Millisecond),
+		)
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/read?hash=5&follow=true")
+		Expect(t, err == nil).To(BeTrue())
+		defer resp.Body.Close()
+
+		time.Sleep(200 * time.Millisecond)
+		Expect(t, t.rangeReader.reader.eofCount() < 30).To(BeTrue())
+	})
+}
+
+type stubRangeReader struct {
+	mu    sync.Mutex
+	low   uint64
+	high  uint64
+	start []reader.Position
+
+	reader    *stubReader
+	positions []reader.Position
+}
+
+func (s *stubRangeReader) ReadRange(low, high uint64, start ...reader.Position) reader.ResumableReader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.low, s.high, s.start = low, high, start
+	return newPositionedReader(s.reader, start, s.positions)
+}
+
+func (s *stubRangeReader) request() (low, high uint64, start []reader.Position) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.low, s.high, s.start
+}
+
+// stubReader returns its packets and then io.EOF until more are added.
+type stubReader struct {
+	mu      sync.Mutex
+	packets []reader.DataPacket
+	err     error
+	eofs    int
+}
+
+func (s *stubReader) add(packets ...reader.DataPacket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.packets = append(s.packets, packets...)
+}
+
+func (s *stubReader) Read() (reader.DataPacket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.packets) == 0 {
+		if s.err != nil {
+			return reader.DataPacket{}, s.err
+		}
+
+		s.eofs++
+		return reader.DataPacket{}, io.EOF
+	}
+
+	p := s.packets[0]
+	s.packets = s.packets[1:]
+	return p, nil
+}
+
+func (s *stubReader) Close() {}
+
+func (s *stubReader) eofCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.eofs
+}
+
+// positionedReader is a stubReader that reports where to resume it: the
+// last index read from each file, starting from the given positions. When
+// fixed is set, it is reported instead.
+type positionedReader struct {
+	*stubReader
+	fixed []reader.Position
+	read  map[string]uint64
+}
+
+func newPositionedReader(r *stubReader, start, fixed []reader.Position) *positionedReader {
+	read := make(map[string]uint64)
+	for _, p := range start {
+		read[p.Filename] = p.Index
+	}
+
+	return &positionedReader{stubReader: r, fixed: fixed, read: read}
+}
+
+func (p *positionedReader) Read() (reader.DataPacket, error) {
+	data, err := p.stubReader.Read()
+	if err == nil {
+		p.read[data.Filename] = data.Index
+	}
+	return data, err
+}
+
+func (p *positionedReader) Positions() []reader.Position {
+	if p.fixed != nil {
+		return p.fixed
+	}
+
+	var positions []reader.Position
+	for file, index := range p.read {
+		positions = append(positions, reader.Position{Filename: file, Index: index})
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Filename < positions[j].Filename
+	})
+	return positions
+}
@@ -0,0 +1,46 @@
+package reader
+
+import (
+	"log/slog"
+
+	"github.com/poy/petasos/router"
+)
+
+// WithProgressRecorder sets the ProgressRecorder told about every packet
+// read. It defaults to doing nothing.
+func WithProgressRecorder(progress ProgressRecorder) func(c *routeReaderConfig) {
+	return func(c *routeReaderConfig) {
+		c.progress = progress
+	}
+}
+
+// WithTracer sets the Tracer used to span reads and file switches. It
+// defaults to router.NopTracer.
+func WithTracer(tracer router.Tracer) func(c *routeReaderConfig) {
+	return func(c *routeReaderConfig) {
+		c.tracer = tracer
+	}
+}
+
+// WithLogger sets the logger. It defaults to slog.Default().
+func WithLogger(logger *slog.Logger) func(c *routeReaderConfig) {
+	return func(c *routeReaderConfig) {
+		c.logger = logger
+	}
+}
+
+// WithCodec sets the codec used to decode range file names. It defaults to
+// router.JSONCodec.
+func WithCodec(codec router.RangeNameCodec) func(c *routeReaderConfig) {
+	return func(c *routeReaderConfig) {
+		c.codec = codec
+	}
+}
+
+// WithTopic scopes the reader to the topic's range set (see
+// router.TopicCodec).
+func WithTopic(topic router.Topic) func(c *routeReaderConfig) {
+	return func(c *routeReaderConfig) {
+		c.topic = topic
+	}
+}
@@ -1,11 +1,14 @@
 package reader
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"io"
+	"log/slog"
 	"sort"
 
 	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
 )
 
 type Reader interface {
@@ -13,6 +16,13 @@ type Reader interface {
 	Close()
 }
 
+// ResumableReader is the Reader returned by RouteReader.ReadRange.
+// Positions returns where to resume it, to be given to ReadRange again.
+type ResumableReader interface {
+	Reader
+	Positions() []Position
+}
+
 type FileSystem interface {
 	List() (file []string, err error)
 	Reader(name string, startingIndex uint64) (reader Reader, err error)
@@ -24,30 +34,100 @@ type DataPacket struct {
 	Index    uint64
 }
 
+// Position is the index of the last packet read from a file. It is used to
+// resume a read (see ResumableReader).
+type Position struct {
+	Filename string
+	Index    uint64
+}
+
+// ProgressRecorder is told about every packet read and about each retired
+// range once it has been read to the end.
+type ProgressRecorder interface {
+	RecordRead(rn router.RangeName, index uint64)
+	RecordDone(rn router.RangeName)
+}
+
 type RouteReader struct {
-	fs FileSystem
+	fs   FileSystem
+	conf routeReaderConfig
+}
+
+type routeReaderConfig struct {
+	codec    router.RangeNameCodec
+	topic    router.Topic
+	progress ProgressRecorder
+	tracer   router.Tracer
+	logger   *slog.Logger
 }
 
-func NewRouteReader(fs FileSystem) *RouteReader {
+type RouteReaderOpts func(c *routeReaderConfig)
+
+func NewRouteReader(fs FileSystem, opts ...RouteReaderOpts) *RouteReader {
+	conf := routeReaderConfig{
+		codec:    router.JSONCodec{},
+		progress: nopProgress{},
+		tracer:   router.NopTracer{},
+		logger:   slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	conf.codec = conf.topic.Codec(conf.codec)
+
 	return &RouteReader{
-		fs: fs,
+		fs:   fs,
+		conf: conf,
 	}
 }
 
 func (r *RouteReader) ReadFrom(hash uint64) Reader {
-	return newFileReader(hash, r.fs)
+	return r.ReadRange(hash, hash)
+}
+
+// ReadRange reads every range that covers any hash from low to high, oldest
+// term first. Each file with a Position is read from after its index, the
+// rest from the start. Retired ranges older than a file with a Position
+// were read to the end before it, so they are skipped unless they have a
+// Position too.
+func (r *RouteReader) ReadRange(low, high uint64, start ...Position) ResumableReader {
+	fr := newFileReader(low, high, r.fs, r.conf)
+	for _, p := range start {
+		fr.historyIdx[p.Filename] = p.Index
+
+		rn, err := r.conf.codec.Decode(p.Filename)
+		if err != nil {
+			continue
+		}
+		fr.terms[p.Filename] = rn.Term
+
+		if !fr.resuming || rn.Term > fr.resumeTerm {
+			fr.resuming, fr.resumeTerm = true, rn.Term
+		}
+	}
+
+	return fr
 }
 
 type fileReader struct {
-	hash uint64
-	fs   FileSystem
+	low, high uint64
+	fs        FileSystem
+	conf      routeReaderConfig
 
-	currentFile Reader
-	current     string
-	currentIdx  uint64
+	currentFile  Reader
+	currentRange router.RangeName
 
 	history    map[hashRange]bool
 	historyIdx map[string]uint64
+	terms      map[string]uint64
+	done       map[string]bool
+
+	// resuming is set until the first listing of a read resumed from a
+	// file with resumeTerm.
+	resuming   bool
+	resumeTerm uint64
 }
 
 type hashRange struct {
@@ -55,34 +135,36 @@ type hashRange struct {
 	r    router.RangeName
 }
 
-func newFileReader(hash uint64, fs FileSystem) *fileReader {
+func newFileReader(low, high uint64, fs FileSystem, conf routeReaderConfig) *fileReader {
 	return &fileReader{
-		hash:       hash,
+		low:        low,
+		high:       high,
 		fs:         fs,
+		conf:       conf,
 		history:    make(map[hashRange]bool),
 		historyIdx: make(map[string]uint64),
+		terms:      make(map[string]uint64),
+		done:       make(map[string]bool),
 	}
 }
 
 func (r *fileReader) Read() (data DataPacket, err error) {
+	ctx, span := r.conf.tracer.Start(context.Background(), "petasos.reader.Read")
+	defer func() {
+		if err != nil && err != io.EOF {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+	if r.low == r.high {
+		span.SetHash(r.low)
+	}
+
 	for {
 		if r.currentFile == nil {
-			next, err := r.fetchNextFile()
-			if err != nil {
-				return DataPacket{}, err
-			}
-
-			// Grab the next index if we have one
-			idx, ok := r.historyIdx[next.file]
-			if ok {
-				idx++
-			}
-
-			reader, err := r.fs.Reader(next.file, idx)
-			if err != nil {
+			if err := r.switchFile(ctx); err != nil {
 				return DataPacket{}, err
 			}
-			r.currentFile = reader
 		}
 
 		data, err = r.currentFile.Read()
@@ -98,13 +180,101 @@ func (r *fileReader) Read() (data DataPacket, err error) {
 		}
 
 		r.historyIdx[data.Filename] = data.Index
+		r.terms[data.Filename] = r.currentRange.Term
+		r.conf.progress.RecordRead(r.currentRange, data.Index)
+		span.SetRange(r.currentRange)
 
 		return data, nil
 	}
 }
 
+func (r *fileReader) switchFile(ctx context.Context) (err error) {
+	_, span := r.conf.tracer.Start(ctx, "petasos.reader.SwitchFile")
+	defer func() {
+		if err != nil && err != io.EOF {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	next, err := r.fetchNextFile()
+	if err != nil {
+		return err
+	}
+	span.SetRange(next.r)
+
+	// Grab the next index if we have one
+	idx, ok := r.historyIdx[next.file]
+	if ok {
+		idx++
+	}
+
+	reader, err := r.fs.Reader(next.file, idx)
+	if err != nil {
+		r.conf.logger.Error("failed to open range", "range", next.r, "file", next.file, "err", err)
+		return err
+	}
+	r.conf.logger.Debug("reading from range", "range", next.r, "index", idx)
+	r.currentFile = reader
+	r.currentRange = next.r
+
+	return nil
+}
+
+// Positions returns where to resume the read, sorted by file. Retired
+// ranges read to the end are left out once a newer file has a Position, as
+// a resumed read skips them.
+func (r *fileReader) Positions() []Position {
+	newest := r.newestTerm()
+
+	positions := make([]Position, 0, len(r.historyIdx))
+	for file, idx := range r.historyIdx {
+		if r.replaced(file, newest) {
+			continue
+		}
+		positions = append(positions, Position{Filename: file, Index: idx})
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Filename < positions[j].Filename
+	})
+
+	return positions
+}
+
+// newestTerm returns the newest term of the files with a Position that are
+// still being read.
+func (r *fileReader) newestTerm() (newest uint64) {
+	for file := range r.historyIdx {
+		if !r.done[file] && r.terms[file] > newest {
+			newest = r.terms[file]
+		}
+	}
+	return newest
+}
+
+// replaced reports whether the file is a retired range read to the end
+// that is older than the newest term still being read.
+func (r *fileReader) replaced(file string, newest uint64) bool {
+	return r.done[file] && r.terms[file] < newest
+}
+
+// forgetReplaced drops the Positions of the replaced files. They are never
+// read again, so a long read only keeps the Positions it still needs.
+func (r *fileReader) forgetReplaced() {
+	newest := r.newestTerm()
+	for file := range r.historyIdx {
+		if r.replaced(file, newest) {
+			delete(r.historyIdx, file)
+			delete(r.terms, file)
+		}
+	}
+}
+
 func (r *fileReader) Close() {
-	r.currentFile.Close()
+	if r.currentFile != nil {
+		r.currentFile.Close()
+	}
 }
 
 func (r *fileReader) fetchNextFile() (hashRange, error) {
@@ -128,83 +298,85 @@ func (r *fileReader) notInHistory(hr hashRange) bool {
 }
 
 func (r *fileReader) fetchFromRange() (files []hashRange, err error) {
-	ranges, err := r.setupRanges()
+	list, err := r.fs.List()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(ranges) == 0 {
-		return nil, nil
+	snapshot := topology.New(list, r.conf.codec)
+	if invalid := snapshot.Invalid(); len(invalid) > 0 {
+		return nil, fmt.Errorf("non-petasos range: %s", invalid[0])
 	}
 
-	sort.Sort(hashRanges(ranges))
+	r.skipRetired(snapshot)
+	r.recordDone(snapshot)
+	r.forgetReplaced()
 
-	var (
-		matchedRange []hashRange
-		lastRange    hashRange
-	)
-	for _, hashRange := range ranges {
-		if r.hash < hashRange.r.Low || r.hash > hashRange.r.High {
-			continue
+	var matchedRange []hashRange
+	for _, rng := range snapshot.Intersecting(r.low, r.high) {
+		hashRange := hashRange{
+			file: rng.File,
+			r:    rng.Name,
 		}
 
 		if r.notInHistory(hashRange) {
 			matchedRange = append(matchedRange, hashRange)
 		}
-
-		lastRange = hashRange
 	}
 
+	// Once everything has been read, the owners are read again as they are
+	// the only ranges still being written to.
 	if len(matchedRange) == 0 {
-		delete(r.history, lastRange)
+		for _, rng := range snapshot.Owners(r.low, r.high) {
+			delete(r.history, hashRange{file: rng.File, r: rng.Name})
+		}
 	}
 
 	return matchedRange, nil
 }
 
-func (r *fileReader) setupRanges() (ranges []hashRange, err error) {
-	list, err := r.fs.List()
-	if err != nil {
-		return nil, err
+// skipRetired marks the retired ranges older than the file a read resumes
+// from as read, unless they have a Position. They were read to the end
+// before that file was.
+func (r *fileReader) skipRetired(snapshot *topology.Snapshot) {
+	if !r.resuming {
+		return
 	}
+	r.resuming = false
 
-	for _, file := range list {
-		var rn router.RangeName
-		err := json.Unmarshal([]byte(file), &rn)
-		if err != nil {
-			return nil, err
+	for _, rng := range snapshot.Superseded() {
+		if _, ok := r.historyIdx[rng.File]; ok || rng.Name.Term >= r.resumeTerm {
+			continue
 		}
 
-		ranges = append(ranges, hashRange{
-			file: file,
-			r:    rn,
-		})
+		r.history[hashRange{file: rng.File, r: rng.Name}] = true
+		r.done[rng.File] = true
 	}
+}
 
-	return ranges, nil
+// recordDone reports each superseded range that has been read to the end.
+// Nothing is written to a range once it is superseded.
+func (r *fileReader) recordDone(snapshot *topology.Snapshot) {
+	for _, rng := range snapshot.Superseded() {
+		if r.done[rng.File] || r.notInHistory(hashRange{file: rng.File, r: rng.Name}) {
+			continue
+		}
+
+		r.done[rng.File] = true
+		r.conf.progress.RecordDone(rng.Name)
+	}
 }
 
 func (r *fileReader) lowHigh(file string) (low, high uint64, err error) {
-	var rn router.RangeName
-	if err := json.Unmarshal([]byte(file), &rn); err != nil {
+	rn, err := r.conf.codec.Decode(file)
+	if err != nil {
 		return 0, 0, err
 	}
 
 	return rn.Low, rn.High, nil
 }
 
-type hashRanges []hashRange
+type nopProgress struct{}
 
-func (s hashRanges) Len() int {
-	return len(s)
-}
-
-func (s hashRanges) Less(i, j int) bool {
-	return s[i].r.Term < s[j].r.Term
-}
-
-func (s hashRanges) Swap(i, j int) {
-	tmp := s[i]
-	s[i] = s[j]
-	s[j] = tmp
-}
+func (nopProgress) RecordRead(rn router.RangeName, index uint64) {}
+func (nopProgress) RecordDone(rn router.RangeName)               {}
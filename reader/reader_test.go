@@ -12,6 +12,7 @@ import (
 	"github.com/poy/onpar"
 	. "github.com/poy/onpar/expect"
 	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/localfs"
 	"github.com/poy/petasos/reader"
 	"github.com/poy/petasos/router"
 )
@@ -87,6 +88,39 @@ func TestReader(t *testing.T) {
 			)
 		})
 
+		o.Spec("it records progress for each read", func(t TR) {
+			progress := &spyProgress{}
+			r := reader.NewRouteReader(t.mockFileSystem, reader.WithProgressRecorder(progress)).
+				ReadFrom(10000000000000000000)
+
+			t.mockReader.ReadOutput.Data <- reader.DataPacket{Payload: []byte("some-data"), Index: 7}
+			t.mockReader.ReadOutput.Err <- nil
+
+			_, err := r.Read()
+			Expect(t, err == nil).To(BeTrue())
+
+			Expect(t, progress.indexes).To(Equal([]uint64{7}))
+			Expect(t, progress.ranges).To(Equal([]router.RangeName{
+				{Low: 9223372036854775808, High: 10000000000000000000, Term: 0},
+			}))
+		})
+
+		o.Spec("it records each retired range once it has been read", func(t TR) {
+			testhelpers.AlwaysReturn(t.mockReader.ReadOutput.Err, io.EOF)
+			close(t.mockReader.ReadOutput.Data)
+
+			progress := &spyProgress{}
+			r := reader.NewRouteReader(t.mockFileSystem, reader.WithProgressRecorder(progress)).
+				ReadFrom(10000000000000000000)
+
+			r.Read()
+			r.Read()
+
+			Expect(t, progress.done).To(Equal([]router.RangeName{
+				{Low: 9223372036854775808, High: 10000000000000000000, Term: 0},
+			}))
+		})
+
 		o.Spec("it continues to read from the last range", func(t TR) {
 			testhelpers.AlwaysReturn(t.mockReader.ReadOutput.Err, io.EOF)
 			close(t.mockReader.ReadOutput.Data)
@@ -108,6 +142,195 @@ func TestReader(t *testing.T) {
 				Chain(Receive(), MatchJSON(`{"Low":9223372036854775808,"High":18446744073709551615,"Term":2,"Rand":0}`)),
 			)
 		})
+
+		o.Spec("it reads every range in a span of hashes", func(t TR) {
+			testhelpers.AlwaysReturn(t.mockReader.ReadOutput.Err, io.EOF)
+			close(t.mockReader.ReadOutput.Data)
+
+			reader := t.r.ReadRange(0, 10000000000000000000)
+			reader.Read()
+
+			Expect(t, t.mockFileSystem.ReaderInput.Name).To(
+				Chain(Receive(), Equal(buildRangeName(0, 9223372036854775807, 0))),
+			)
+			Expect(t, t.mockFileSystem.ReaderInput.Name).To(
+				Chain(Receive(), Equal(buildRangeName(9223372036854775808, 10000000000000000000, 0))),
+			)
+			Expect(t, t.mockFileSystem.ReaderInput.Name).To(
+				Chain(Receive(), Equal(buildRangeName(9223372036854775808, 18446744073709551615, 2))),
+			)
+		})
+
+		o.Spec("it resumes after the given positions", func(t TR) {
+			testhelpers.AlwaysReturn(t.mockReader.ReadOutput.Err, io.EOF)
+			close(t.mockReader.ReadOutput.Data)
+
+			reader := t.r.ReadRange(10000000000000000000, 10000000000000000000,
+				reader.Position{
+					Filename: buildRangeName(9223372036854775808, 10000000000000000000, 0),
+					Index:    3,
+				},
+				reader.Position{
+					Filename: buildRangeName(9223372036854775808, 18446744073709551615, 2),
+					Index:    7,
+				},
+			)
+			reader.Read()
+
+			Expect(t, t.mockFileSystem.ReaderInput.StartingIndex).To(Chain(Receive(), Equal(uint64(4))))
+			Expect(t, t.mockFileSystem.ReaderInput.StartingIndex).To(Chain(Receive(), Equal(uint64(8))))
+		})
+
+		o.Spec("it skips retired ranges older than a resumed file", func(t TR) {
+			testhelpers.AlwaysReturn(t.mockReader.ReadOutput.Err, io.EOF)
+			close(t.mockReader.ReadOutput.Data)
+
+			reader := t.r.ReadRange(10000000000000000000, 10000000000000000000, reader.Position{
+				Filename: buildRangeName(9223372036854775808, 18446744073709551615, 2),
+				Index:    7,
+			})
+			reader.Read()
+
+			Expect(t, t.mockFileSystem.ReaderInput.Name).To(
+				Chain(Receive(), Equal(buildRangeName(9223372036854775808, 18446744073709551615, 2))),
+			)
+			Expect(t, t.mockFileSystem.ReaderInput.StartingIndex).To(Chain(Receive(), Equal(uint64(8))))
+		})
+
+		o.Spec("it leaves retired ranges out of the positions once a newer file is read", func(t TR) {
+			retired := buildRangeName(9223372036854775808, 10000000000000000000, 0)
+			owner := buildRangeName(9223372036854775808, 18446744073709551615, 2)
+			r := t.r.ReadFrom(10000000000000000000)
+			positions := r.(interface{ Positions() []reader.Position })
+
+			t.mockReader.ReadOutput.Data <- reader.DataPacket{Filename: retired, Index: 0}
+			t.mockReader.ReadOutput.Err <- nil
+			_, err := r.Read()
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, positions.Positions()).To(Equal([]reader.Position{{Filename: retired, Index: 0}}))
+
+			t.mockReader.ReadOutput.Data <- reader.DataPacket{}
+			t.mockReader.ReadOutput.Err <- io.EOF
+			t.mockReader.ReadOutput.Data <- reader.DataPacket{Filename: owner, Index: 0}
+			t.mockReader.ReadOutput.Err <- nil
+			_, err = r.Read()
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, positions.Positions()).To(Equal([]reader.Position{{Filename: owner, Index: 0}}))
+		})
+	})
+}
+
+type spyProgress struct {
+	ranges  []router.RangeName
+	indexes []uint64
+	done    []router.RangeName
+}
+
+func (s *spyProgress) RecordRead(rn router.RangeName, index uint64) {
+	s.ranges = append(s.ranges, rn)
+	s.indexes = append(s.indexes, index)
+}
+
+func (s *spyProgress) RecordDone(rn router.RangeName) {
+	s.done = append(s.done, rn)
+}
+
+func TestReaderTopic(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it only reads ranges in the topic", func(t *testing.T) {
+		mockFileSystem := newMockFileSystem()
+		mockReader := newMockReader()
+		codec, err := router.NewTopicCodec("some-topic", router.JSONCodec{})
+		Expect(t, err == nil).To(BeTrue())
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, []string{
+			"other-topic/" + buildRangeName(0, 18446744073709551615, 5),
+			codec.Encode(router.RangeName{Low: 0, High: 18446744073709551615, Term: 1}),
+			buildRangeName(0, 18446744073709551615, 3),
+		})
+		close(mockFileSystem.ListOutput.Err)
+
+		testhelpers.AlwaysReturn(mockFileSystem.ReaderOutput.Reader, mockReader)
+		close(mockFileSystem.ReaderOutput.Err)
+
+		mockReader.ReadOutput.Data <- reader.DataPacket{Payload: []byte("some-data")}
+		mockReader.ReadOutput.Err <- nil
+
+		topic, err := router.ParseTopic("some-topic")
+		Expect(t, err == nil).To(BeTrue())
+
+		r := reader.NewRouteReader(mockFileSystem, reader.WithTopic(topic)).ReadFrom(100)
+		data, err := r.Read()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, data.Payload).To(Equal([]byte("some-data")))
+
+		Expect(t, mockFileSystem.ReaderInput.Name).To(
+			Chain(Receive(), Equal(codec.Encode(router.RangeName{Low: 0, High: 18446744073709551615, Term: 1}))),
+		)
+	})
+
+	o.Spec("it shares a FileSystem with a reader without a topic", func(t *testing.T) {
+		mockFileSystem := newMockFileSystem()
+		mockReader := newMockReader()
+		topic, err := router.ParseTopic("some-topic")
+		Expect(t, err == nil).To(BeTrue())
+		topicFile := topic.Codec(router.JSONCodec{}).Encode(router.RangeName{Low: 0, High: 18446744073709551615, Term: 1})
+
+		testhelpers.AlwaysReturn(mockFileSystem.ListOutput.File, []string{
+			topicFile,
+			buildRangeName(0, 18446744073709551615, 3),
+		})
+		close(mockFileSystem.ListOutput.Err)
+
+		testhelpers.AlwaysReturn(mockFileSystem.ReaderOutput.Reader, mockReader)
+		close(mockFileSystem.ReaderOutput.Err)
+
+		testhelpers.AlwaysReturn(mockReader.ReadOutput.Data, reader.DataPacket{Payload: []byte("some-data")})
+		close(mockReader.ReadOutput.Err)
+
+		_, err = reader.NewRouteReader(mockFileSystem).ReadFrom(100).Read()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, mockFileSystem.ReaderInput.Name).To(
+			Chain(Receive(), Equal(buildRangeName(0, 18446744073709551615, 3))),
+		)
+
+		_, err = reader.NewRouteReader(mockFileSystem, reader.WithTopic(topic)).ReadFrom(100).Read()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, mockFileSystem.ReaderInput.Name).To(
+			Chain(Receive(), Equal(topicFile)),
+		)
+	})
+}
+
+func TestReaderRetirement(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it only keeps the position of the newest range as ranges are retired", func(t *testing.T) {
+		fs, err := localfs.New(t.TempDir())
+		Expect(t, err == nil).To(BeTrue())
+
+		r := reader.NewRouteReader(fs).ReadRange(100, 100)
+		defer r.Close()
+
+		for term := uint64(0); term < 5; term++ {
+			file := buildRangeName(0, 18446744073709551615, term)
+			Expect(t, fs.Create(file)).To(BeNil())
+
+			w, err := fs.Writer(file)
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, w.Write([]byte("some-data"))).To(BeNil())
+			w.Close()
+
+			data, err := r.Read()
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, data.Filename).To(Equal(file))
+			Expect(t, r.Positions()).To(Equal([]reader.Position{{Filename: file, Index: 0}}))
+		}
 	})
 }
 
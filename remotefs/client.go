@@ -0,0 +1,328 @@
+package remotefs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/remotefs/remotefspb"
+	"github.com/poy/petasos/router"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Client is a FileSystem on a storage node serving a Server. It implements
+// the router, reader and maintainer FileSystems.
+type Client struct {
+	dialOpts []grpc.DialOption
+	timeout  time.Duration
+
+	conn   *grpc.ClientConn
+	client remotefspb.FileSystemClient
+
+	mu      sync.Mutex
+	writers map[string]*remoteWriter
+}
+
+type ClientOpts func(c *Client)
+
+// WithDialOptions sets the options used to connect to the storage node. It
+// defaults to an insecure connection.
+func WithDialOptions(opts ...grpc.DialOption) func(c *Client) {
+	return func(c *Client) {
+		c.dialOpts = opts
+	}
+}
+
+// WithTimeout sets the timeout for List, Create, Len, each write and
+// opening a reader. It defaults to 5 seconds.
+func WithTimeout(timeout time.Duration) func(c *Client) {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+func NewClient(addr string, opts ...ClientOpts) (*Client, error) {
+	c := &Client{
+		dialOpts: []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		},
+		timeout: 5 * time.Second,
+		writers: make(map[string]*remoteWriter),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	conn, err := grpc.NewClient(addr, c.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+	c.client = remotefspb.NewFileSystemClient(conn)
+
+	return c, nil
+}
+
+func (c *Client) List() (file []string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := c.client.List(ctx, &remotefspb.ListRequest{})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	return resp.GetFiles(), nil
+}
+
+func (c *Client) Create(file string) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	_, err = c.client.Create(ctx, &remotefspb.CreateRequest{File: file})
+	return fromStatus(err)
+}
+
+// Len returns how many complete records the file has.
+func (c *Client) Len(name string) (length uint64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Len(ctx, &remotefspb.LenRequest{File: name})
+	if err != nil {
+		return 0, fromStatus(err)
+	}
+
+	return resp.GetLength(), nil
+}
+
+// Writer opens a stream to append records to an existing file. Every
+// Writer for a file shares the same stream until they are all closed. Each
+// Write waits for the storage node to write the record.
+func (c *Client) Writer(name string) (writer router.Writer, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, ok := c.writers[name]
+	if !ok {
+		w, err = c.openWriter(name)
+		if err != nil {
+			return nil, err
+		}
+		c.writers[name] = w
+	}
+	w.refs++
+
+	return &writerHandle{w: w}, nil
+}
+
+func (c *Client) openWriter(name string) (*remoteWriter, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := c.client.Write(ctx)
+	if err != nil {
+		cancel()
+		return nil, fromStatus(err)
+	}
+
+	w := &remoteWriter{
+		client:  c,
+		name:    name,
+		stream:  stream,
+		cancel:  cancel,
+		timeout: c.timeout,
+	}
+
+	if err := w.send(&remotefspb.WriteRequest{File: name}); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// release closes the stream once every Writer sharing it has been closed.
+func (c *Client) release(w *remoteWriter) {
+	c.mu.Lock()
+	w.refs--
+	last := w.refs == 0
+	if last && c.writers[w.name] == w {
+		delete(c.writers, w.name)
+	}
+	c.mu.Unlock()
+
+	if last {
+		w.close()
+	}
+}
+
+// detach stops new Writers from sharing a failed stream so they open a new
+// one.
+func (c *Client) detach(w *remoteWriter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.writers[w.name] == w {
+		delete(c.writers, w.name)
+	}
+}
+
+// Reader streams the file's records starting at startingIndex. Read returns
+// io.EOF once it has read every record the storage node had when the
+// Reader was opened. Like localfs, it returns io.EOF if the file has fewer
+// than startingIndex records.
+func (c *Client) Reader(name string, startingIndex uint64) (r reader.Reader, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := c.client.Read(ctx, &remotefspb.ReadRequest{
+		File:          name,
+		StartingIndex: startingIndex,
+	})
+	if err != nil {
+		cancel()
+		return nil, fromStatus(err)
+	}
+
+	// The first response confirms the file is open.
+	timer := time.AfterFunc(c.timeout, cancel)
+	_, err = stream.Recv()
+	timer.Stop()
+	if err != nil {
+		cancel()
+		return nil, fromStatus(err)
+	}
+
+	return &remoteReader{stream: stream, cancel: cancel}, nil
+}
+
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+// remoteWriter is the write stream shared by a file's Writers.
+type remoteWriter struct {
+	client *Client
+	name   string
+	refs   int
+
+	mu      sync.Mutex
+	stream  grpc.BidiStreamingClient[remotefspb.WriteRequest, remotefspb.WriteResponse]
+	cancel  func()
+	timeout time.Duration
+}
+
+// write sends the record. Once it fails the stream is done, so later
+// Writers for the file open a new one.
+func (w *remoteWriter) write(data []byte) error {
+	err := w.send(&remotefspb.WriteRequest{Payload: data})
+	if err != nil {
+		w.client.detach(w)
+	}
+
+	return err
+}
+
+// send sends the request and waits for its response. The stream is
+// cancelled if that takes longer than the timeout.
+func (w *remoteWriter) send(req *remotefspb.WriteRequest) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	timer := time.AfterFunc(w.timeout, w.cancel)
+	defer timer.Stop()
+
+	if err := w.stream.Send(req); err != nil {
+		// The server's error is only available from Recv.
+		if _, rerr := w.stream.Recv(); rerr != nil && rerr != io.EOF {
+			return fromStatus(rerr)
+		}
+		return fromStatus(err)
+	}
+
+	if _, err := w.stream.Recv(); err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("storage node closed the stream")
+		}
+		return fromStatus(err)
+	}
+
+	return nil
+}
+
+func (w *remoteWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stream.CloseSend()
+	w.cancel()
+}
+
+// writerHandle is the router.Writer for a shared remoteWriter.
+type writerHandle struct {
+	w    *remoteWriter
+	once sync.Once
+}
+
+func (h *writerHandle) Write(data []byte) (err error) {
+	return h.w.write(data)
+}
+
+func (h *writerHandle) Close() {
+	h.once.Do(func() {
+		h.w.client.release(h.w)
+	})
+}
+
+type remoteReader struct {
+	stream grpc.ServerStreamingClient[remotefspb.ReadResponse]
+	cancel func()
+}
+
+func (r *remoteReader) Read() (data reader.DataPacket, err error) {
+	resp, err := r.stream.Recv()
+	if err == io.EOF {
+		return reader.DataPacket{}, io.EOF
+	}
+
+	if err != nil {
+		return reader.DataPacket{}, fromStatus(err)
+	}
+
+	p := resp.GetPacket()
+	return reader.DataPacket{
+		Payload:  p.GetPayload(),
+		Filename: p.GetFilename(),
+		Index:    p.GetIndex(),
+	}, nil
+}
+
+func (r *remoteReader) Close() {
+	r.cancel()
+}
+
+// fromStatus wraps the errors the Server distinguishes so errors.Is works
+// as it would against the storage node's FileSystem.
+func fromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.OutOfRange:
+		return io.EOF
+	case codes.NotFound:
+		return fmt.Errorf("%w: %s", fs.ErrNotExist, status.Convert(err).Message())
+	case codes.AlreadyExists:
+		return fmt.Errorf("%w: %s", fs.ErrExist, status.Convert(err).Message())
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", fs.ErrInvalid, status.Convert(err).Message())
+	default:
+		return err
+	}
+}
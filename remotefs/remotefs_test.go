@@ -0,0 +1,203 @@
+package remotefs_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/localfs"
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/remotefs"
+	"github.com/poy/petasos/remotefs/remotefspb"
+	"github.com/poy/petasos/router"
+	"google.golang.org/grpc"
+)
+
+var someFile = router.PathCodec{}.Encode(router.RangeName{Low: 0, High: 10, Term: 1})
+
+type TF struct {
+	*testing.T
+
+	local   *localfs.FileSystem
+	server  *grpc.Server
+	client  *remotefs.Client
+	streams *atomic.Int64
+}
+
+func TestRemoteFileSystem(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TF {
+		local, err := localfs.New(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		streams := new(atomic.Int64)
+		server := grpc.NewServer(grpc.StreamInterceptor(
+			func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				streams.Add(1)
+				return handler(srv, ss)
+			},
+		))
+		remotefspb.RegisterFileSystemServer(server, remotefs.NewServer(local))
+		go server.Serve(lis)
+
+		client, err := remotefs.NewClient(lis.Addr().String(), remotefs.WithTimeout(time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return TF{
+			T:       t,
+			local:   local,
+			server:  server,
+			client:  client,
+			streams: streams,
+		}
+	})
+
+	o.AfterEach(func(t TF) {
+		t.client.Close()
+		t.server.Stop()
+	})
+
+	o.Spec("it creates and lists files", func(t TF) {
+		Expect(t, t.client.Create("some-topic/"+someFile) == nil).To(BeTrue())
+
+		files, err := t.client.List()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, files).To(Equal([]string{"some-topic/" + someFile}))
+	})
+
+	o.Spec("it reports a file that already exists", func(t TF) {
+		t.client.Create(someFile)
+
+		err := t.client.Create(someFile)
+		Expect(t, errors.Is(err, fs.ErrExist)).To(BeTrue())
+	})
+
+	o.Spec("it reads back what was written", func(t TF) {
+		t.client.Create(someFile)
+
+		w, err := t.client.Writer(someFile)
+		Expect(t, err == nil).To(BeTrue())
+		defer w.Close()
+
+		for _, payload := range []string{"a", "b", "c"} {
+			Expect(t, w.Write([]byte(payload)) == nil).To(BeTrue())
+		}
+
+		r, err := t.client.Reader(someFile, 1)
+		Expect(t, err == nil).To(BeTrue())
+		defer r.Close()
+
+		for i, payload := range []string{"b", "c"} {
+			data, err := r.Read()
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, data).To(Equal(reader.DataPacket{
+				Payload:  []byte(payload),
+				Filename: someFile,
+				Index:    uint64(i + 1),
+			}))
+		}
+
+		_, err = r.Read()
+		Expect(t, err).To(Equal(io.EOF))
+	})
+
+	o.Spec("it returns how many records a file has", func(t TF) {
+		t.client.Create(someFile)
+
+		w, err := t.client.Writer(someFile)
+		Expect(t, err == nil).To(BeTrue())
+		defer w.Close()
+
+		for _, payload := range []string{"a", "b", "c"} {
+			Expect(t, w.Write([]byte(payload)) == nil).To(BeTrue())
+		}
+
+		length, err := t.client.Len(someFile)
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, length).To(Equal(uint64(3)))
+	})
+
+	o.Spec("it shares one stream between a file's writers", func(t TF) {
+		t.client.Create(someFile)
+
+		a, err := t.client.Writer(someFile)
+		Expect(t, err == nil).To(BeTrue())
+		b, err := t.client.Writer(someFile)
+		Expect(t, err == nil).To(BeTrue())
+
+		Expect(t, a.Write([]byte("a")) == nil).To(BeTrue())
+		a.Close()
+		Expect(t, b.Write([]byte("b")) == nil).To(BeTrue())
+		b.Close()
+		Expect(t, t.streams.Load()).To(Equal(int64(1)))
+
+		c, err := t.client.Writer(someFile)
+		Expect(t, err == nil).To(BeTrue())
+		defer c.Close()
+		Expect(t, c.Write([]byte("c")) == nil).To(BeTrue())
+		Expect(t, t.streams.Load()).To(Equal(int64(2)))
+	})
+
+	o.Spec("it returns io.EOF when opening past the end of a file", func(t TF) {
+		t.client.Create(someFile)
+
+		_, err := t.client.Reader(someFile, 1)
+		Expect(t, err).To(Equal(io.EOF))
+	})
+
+	o.Spec("it rejects names that are not range files", func(t TF) {
+		for _, name := range []string{
+			"some-file",
+			"../" + someFile,
+			"/tmp/" + someFile,
+			"some-topic/../../" + someFile,
+			`some-topic\` + someFile,
+		} {
+			err := t.client.Create(name)
+			Expect(t, errors.Is(err, fs.ErrInvalid)).To(BeTrue())
+
+			_, err = t.client.Writer(name)
+			Expect(t, errors.Is(err, fs.ErrInvalid)).To(BeTrue())
+
+			_, err = t.client.Reader(name, 0)
+			Expect(t, errors.Is(err, fs.ErrInvalid)).To(BeTrue())
+
+			_, err = t.client.Len(name)
+			Expect(t, errors.Is(err, fs.ErrInvalid)).To(BeTrue())
+		}
+
+		files, err := t.local.List()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, files).To(HaveLen(0))
+	})
+
+	o.Spec("it reports a missing file", func(t TF) {
+		_, err := t.client.Writer(someFile)
+		Expect(t, errors.Is(err, fs.ErrNotExist)).To(BeTrue())
+
+		_, err = t.client.Reader(someFile, 0)
+		Expect(t, errors.Is(err, fs.ErrNotExist)).To(BeTrue())
+
+		_, err = t.client.Len(someFile)
+		Expect(t, errors.Is(err, fs.ErrNotExist)).To(BeTrue())
+	})
+}
@@ -0,0 +1,5 @@
+// Package remotefspb contains the generated gRPC service for remote file
+// systems.
+package remotefspb
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. remotefs.proto
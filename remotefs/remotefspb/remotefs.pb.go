@@ -0,0 +1,621 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: remotefs.proto
+
+package remotefspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_remotefs_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{0}
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []string               `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_remotefs_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListResponse) GetFiles() []string {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type CreateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateRequest) Reset() {
+	*x = CreateRequest{}
+	mi := &file_remotefs_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRequest) ProtoMessage() {}
+
+func (x *CreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRequest.ProtoReflect.Descriptor instead.
+func (*CreateRequest) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+type CreateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateResponse) Reset() {
+	*x = CreateResponse{}
+	mi := &file_remotefs_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateResponse) ProtoMessage() {}
+
+func (x *CreateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateResponse.ProtoReflect.Descriptor instead.
+func (*CreateResponse) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{3}
+}
+
+type WriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteRequest) Reset() {
+	*x = WriteRequest{}
+	mi := &file_remotefs_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteRequest) ProtoMessage() {}
+
+func (x *WriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteRequest.ProtoReflect.Descriptor instead.
+func (*WriteRequest) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WriteRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *WriteRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type WriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteResponse) Reset() {
+	*x = WriteResponse{}
+	mi := &file_remotefs_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteResponse) ProtoMessage() {}
+
+func (x *WriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteResponse.ProtoReflect.Descriptor instead.
+func (*WriteResponse) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{5}
+}
+
+type ReadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	StartingIndex uint64                 `protobuf:"varint,2,opt,name=starting_index,json=startingIndex,proto3" json:"starting_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadRequest) Reset() {
+	*x = ReadRequest{}
+	mi := &file_remotefs_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadRequest) ProtoMessage() {}
+
+func (x *ReadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadRequest.ProtoReflect.Descriptor instead.
+func (*ReadRequest) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ReadRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *ReadRequest) GetStartingIndex() uint64 {
+	if x != nil {
+		return x.StartingIndex
+	}
+	return 0
+}
+
+type ReadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Packet        *DataPacket            `protobuf:"bytes,1,opt,name=packet,proto3" json:"packet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadResponse) Reset() {
+	*x = ReadResponse{}
+	mi := &file_remotefs_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadResponse) ProtoMessage() {}
+
+func (x *ReadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadResponse.ProtoReflect.Descriptor instead.
+func (*ReadResponse) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ReadResponse) GetPacket() *DataPacket {
+	if x != nil {
+		return x.Packet
+	}
+	return nil
+}
+
+type DataPacket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payload       []byte                 `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`
+	Index         uint64                 `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataPacket) Reset() {
+	*x = DataPacket{}
+	mi := &file_remotefs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataPacket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataPacket) ProtoMessage() {}
+
+func (x *DataPacket) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataPacket.ProtoReflect.Descriptor instead.
+func (*DataPacket) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DataPacket) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *DataPacket) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *DataPacket) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+type LenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LenRequest) Reset() {
+	*x = LenRequest{}
+	mi := &file_remotefs_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LenRequest) ProtoMessage() {}
+
+func (x *LenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LenRequest.ProtoReflect.Descriptor instead.
+func (*LenRequest) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LenRequest) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+type LenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Length        uint64                 `protobuf:"varint,1,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LenResponse) Reset() {
+	*x = LenResponse{}
+	mi := &file_remotefs_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LenResponse) ProtoMessage() {}
+
+func (x *LenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_remotefs_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LenResponse.ProtoReflect.Descriptor instead.
+func (*LenResponse) Descriptor() ([]byte, []int) {
+	return file_remotefs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LenResponse) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+var File_remotefs_proto protoreflect.FileDescriptor
+
+const file_remotefs_proto_rawDesc = "" +
+	"\n" +
+	"\x0eremotefs.proto\x12\x10petasos.remotefs\"\r\n" +
+	"\vListRequest\"$\n" +
+	"\fListResponse\x12\x14\n" +
+	"\x05files\x18\x01 \x03(\tR\x05files\"#\n" +
+	"\rCreateRequest\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\"\x10\n" +
+	"\x0eCreateResponse\"<\n" +
+	"\fWriteRequest\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\fR\apayload\"\x0f\n" +
+	"\rWriteResponse\"H\n" +
+	"\vReadRequest\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12%\n" +
+	"\x0estarting_index\x18\x02 \x01(\x04R\rstartingIndex\"D\n" +
+	"\fReadResponse\x124\n" +
+	"\x06packet\x18\x01 \x01(\v2\x1c.petasos.remotefs.DataPacketR\x06packet\"X\n" +
+	"\n" +
+	"DataPacket\x12\x18\n" +
+	"\apayload\x18\x01 \x01(\fR\apayload\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x14\n" +
+	"\x05index\x18\x03 \x01(\x04R\x05index\" \n" +
+	"\n" +
+	"LenRequest\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\"%\n" +
+	"\vLenResponse\x12\x16\n" +
+	"\x06length\x18\x01 \x01(\x04R\x06length2\xfb\x02\n" +
+	"\n" +
+	"FileSystem\x12E\n" +
+	"\x04List\x12\x1d.petasos.remotefs.ListRequest\x1a\x1e.petasos.remotefs.ListResponse\x12K\n" +
+	"\x06Create\x12\x1f.petasos.remotefs.CreateRequest\x1a .petasos.remotefs.CreateResponse\x12L\n" +
+	"\x05Write\x12\x1e.petasos.remotefs.WriteRequest\x1a\x1f.petasos.remotefs.WriteResponse(\x010\x01\x12G\n" +
+	"\x04Read\x12\x1d.petasos.remotefs.ReadRequest\x1a\x1e.petasos.remotefs.ReadResponse0\x01\x12B\n" +
+	"\x03Len\x12\x1c.petasos.remotefs.LenRequest\x1a\x1d.petasos.remotefs.LenResponseB,Z*github.com/poy/petasos/remotefs/remotefspbb\x06proto3"
+
+var (
+	file_remotefs_proto_rawDescOnce sync.Once
+	file_remotefs_proto_rawDescData []byte
+)
+
+func file_remotefs_proto_rawDescGZIP() []byte {
+	file_remotefs_proto_rawDescOnce.Do(func() {
+		file_remotefs_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_remotefs_proto_rawDesc), len(file_remotefs_proto_rawDesc)))
+	})
+	return file_remotefs_proto_rawDescData
+}
+
+var file_remotefs_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_remotefs_proto_goTypes = []any{
+	(*ListRequest)(nil),    // 0: petasos.remotefs.ListRequest
+	(*ListResponse)(nil),   // 1: petasos.remotefs.ListResponse
+	(*CreateRequest)(nil),  // 2: petasos.remotefs.CreateRequest
+	(*CreateResponse)(nil), // 3: petasos.remotefs.CreateResponse
+	(*WriteRequest)(nil),   // 4: petasos.remotefs.WriteRequest
+	(*WriteResponse)(nil),  // 5: petasos.remotefs.WriteResponse
+	(*ReadRequest)(nil),    // 6: petasos.remotefs.ReadRequest
+	(*ReadResponse)(nil),   // 7: petasos.remotefs.ReadResponse
+	(*DataPacket)(nil),     // 8: petasos.remotefs.DataPacket
+	(*LenRequest)(nil),     // 9: petasos.remotefs.LenRequest
+	(*LenResponse)(nil),    // 10: petasos.remotefs.LenResponse
+}
+var file_remotefs_proto_depIdxs = []int32{
+	8,  // 0: petasos.remotefs.ReadResponse.packet:type_name -> petasos.remotefs.DataPacket
+	0,  // 1: petasos.remotefs.FileSystem.List:input_type -> petasos.remotefs.ListRequest
+	2,  // 2: petasos.remotefs.FileSystem.Create:input_type -> petasos.remotefs.CreateRequest
+	4,  // 3: petasos.remotefs.FileSystem.Write:input_type -> petasos.remotefs.WriteRequest
+	6,  // 4: petasos.remotefs.FileSystem.Read:input_type -> petasos.remotefs.ReadRequest
+	9,  // 5: petasos.remotefs.FileSystem.Len:input_type -> petasos.remotefs.LenRequest
+	1,  // 6: petasos.remotefs.FileSystem.List:output_type -> petasos.remotefs.ListResponse
+	3,  // 7: petasos.remotefs.FileSystem.Create:output_type -> petasos.remotefs.CreateResponse
+	5,  // 8: petasos.remotefs.FileSystem.Write:output_type -> petasos.remotefs.WriteResponse
+	7,  // 9: petasos.remotefs.FileSystem.Read:output_type -> petasos.remotefs.ReadResponse
+	10, // 10: petasos.remotefs.FileSystem.Len:output_type -> petasos.remotefs.LenResponse
+	6,  // [6:11] is the sub-list for method output_type
+	1,  // [1:6] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_remotefs_proto_init() }
+func file_remotefs_proto_init() {
+	if File_remotefs_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_remotefs_proto_rawDesc), len(file_remotefs_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_remotefs_proto_goTypes,
+		DependencyIndexes: file_remotefs_proto_depIdxs,
+		MessageInfos:      file_remotefs_proto_msgTypes,
+	}.Build()
+	File_remotefs_proto = out.File
+	file_remotefs_proto_goTypes = nil
+	file_remotefs_proto_depIdxs = nil
+}
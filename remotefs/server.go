@@ -0,0 +1,190 @@
+// Package remotefs serves a FileSystem over gRPC so routers, readers and
+// maintainers can run separately from the storage node holding the range
+// files.
+package remotefs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/remotefs/remotefspb"
+	"github.com/poy/petasos/router"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FileSystem is implemented by localfs.FileSystem. It must be safe for
+// concurrent use.
+type FileSystem interface {
+	List() (file []string, err error)
+	Create(file string) (err error)
+	Writer(name string) (writer router.Writer, err error)
+	Reader(name string, startingIndex uint64) (reader reader.Reader, err error)
+	Len(name string) (length uint64, err error)
+}
+
+// Server serves a FileSystem. Register it with
+// remotefspb.RegisterFileSystemServer.
+type Server struct {
+	remotefspb.UnimplementedFileSystemServer
+
+	fs FileSystem
+}
+
+func NewServer(fs FileSystem) *Server {
+	return &Server{
+		fs: fs,
+	}
+}
+
+func (s *Server) List(ctx context.Context, req *remotefspb.ListRequest) (*remotefspb.ListResponse, error) {
+	files, err := s.fs.List()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &remotefspb.ListResponse{Files: files}, nil
+}
+
+func (s *Server) Create(ctx context.Context, req *remotefspb.CreateRequest) (*remotefspb.CreateResponse, error) {
+	if err := validName(req.GetFile()); err != nil {
+		return nil, err
+	}
+
+	if err := s.fs.Create(req.GetFile()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &remotefspb.CreateResponse{}, nil
+}
+
+func (s *Server) Write(stream grpc.BidiStreamingServer[remotefspb.WriteRequest, remotefspb.WriteResponse]) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	if err := validName(req.GetFile()); err != nil {
+		return err
+	}
+
+	w, err := s.fs.Writer(req.GetFile())
+	if err != nil {
+		return toStatus(err)
+	}
+	defer w.Close()
+
+	if err := stream.Send(&remotefspb.WriteResponse{}); err != nil {
+		return err
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := w.Write(req.GetPayload()); err != nil {
+			return toStatus(err)
+		}
+
+		if err := stream.Send(&remotefspb.WriteResponse{}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) Read(req *remotefspb.ReadRequest, stream grpc.ServerStreamingServer[remotefspb.ReadResponse]) error {
+	if err := validName(req.GetFile()); err != nil {
+		return err
+	}
+
+	r, err := s.fs.Reader(req.GetFile(), req.GetStartingIndex())
+	if err != nil {
+		return toStatus(err)
+	}
+	defer r.Close()
+
+	if err := stream.Send(&remotefspb.ReadResponse{}); err != nil {
+		return err
+	}
+
+	for {
+		data, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return toStatus(err)
+		}
+
+		err = stream.Send(&remotefspb.ReadResponse{
+			Packet: &remotefspb.DataPacket{
+				Payload:  data.Payload,
+				Filename: data.Filename,
+				Index:    data.Index,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) Len(ctx context.Context, req *remotefspb.LenRequest) (*remotefspb.LenResponse, error) {
+	if err := validName(req.GetFile()); err != nil {
+		return nil, err
+	}
+
+	length, err := s.fs.Len(req.GetFile())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &remotefspb.LenResponse{Length: length}, nil
+}
+
+// codecs are the range name codecs a served file may be named with.
+// validName rejects a name unless it is a range name, optionally below a
+// slash separated topic. Anything else (e.g., an absolute path or one with
+// ".." elements) could reach outside of the storage directory.
+func validName(file string) error {
+	if !fs.ValidPath(file) || strings.Contains(file, `\`) {
+		return status.Errorf(codes.InvalidArgument, "invalid file name: %q", file)
+	}
+
+	if _, err := (router.AnyCodec{}).Decode(path.Base(file)); err != nil {
+		return status.Errorf(codes.InvalidArgument, "not a range name: %q", file)
+	}
+
+	return nil
+}
+
+// toStatus keeps the errors callers check for (e.g., fs.ErrExist from
+// Create or io.EOF from opening a Reader past the end of a file)
+// distinguishable on the client.
+func toStatus(err error) error {
+	switch {
+	case err == io.EOF:
+		return status.Error(codes.OutOfRange, err.Error())
+	case errors.Is(err, fs.ErrNotExist):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, fs.ErrExist):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, fs.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
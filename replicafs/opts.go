@@ -0,0 +1,37 @@
+package replicafs
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithWriteQuorum sets how many replicas must accept each create and write.
+// It defaults to a majority, as does 0.
+func WithWriteQuorum(quorum int) func(c *fileSystemConfig) {
+	return func(c *fileSystemConfig) {
+		c.writeQuorum = quorum
+	}
+}
+
+// WithRetryInterval sets how long a failed replica is passed over when
+// opening writers and readers. It defaults to 10 seconds.
+func WithRetryInterval(interval time.Duration) func(c *fileSystemConfig) {
+	return func(c *fileSystemConfig) {
+		c.retryInterval = interval
+	}
+}
+
+// WithLogger sets the logger. It defaults to slog.Default().
+func WithLogger(logger *slog.Logger) func(c *fileSystemConfig) {
+	return func(c *fileSystemConfig) {
+		c.logger = logger
+	}
+}
+
+// WithClock sets the clock used to track failed replicas. It defaults to
+// time.Now.
+func WithClock(now func() time.Time) func(c *fileSystemConfig) {
+	return func(c *fileSystemConfig) {
+		c.now = now
+	}
+}
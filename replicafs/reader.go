@@ -0,0 +1,170 @@
+package replicafs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/poy/petasos/reader"
+)
+
+// Reader reads the file from a healthy replica. When that replica fails,
+// reading continues from the same index on another replica. When it runs
+// out of records, the other replicas are checked for more at most once per
+// retry interval. Like localfs, it returns io.EOF if no replica has
+// startingIndex records.
+func (f *FileSystem) Reader(name string, startingIndex uint64) (r reader.Reader, err error) {
+	rr := &replicaReader{
+		fs:   f,
+		name: name,
+		next: startingIndex,
+	}
+
+	if err := rr.open(); err != nil {
+		return nil, err
+	}
+
+	return rr, nil
+}
+
+type replicaReader struct {
+	fs   *FileSystem
+	name string
+	next uint64
+
+	current *replica
+	reader  reader.Reader
+
+	// pending is a packet read from the replica switched to.
+	pending *reader.DataPacket
+
+	// checkedAt is when the other replicas were last checked for records
+	// past the end of the current one.
+	checkedAt time.Time
+}
+
+func (r *replicaReader) Read() (data reader.DataPacket, err error) {
+	if r.pending != nil {
+		data, r.pending = *r.pending, nil
+		r.next = data.Index + 1
+		return data, nil
+	}
+
+	if r.reader == nil {
+		rd, err := r.current.Reader(r.name, r.next)
+		r.fs.record(r.current, err)
+		if err != nil {
+			return r.failover(err)
+		}
+		r.reader = rd
+	}
+
+	data, err = r.reader.Read()
+	if err == nil {
+		r.next = data.Index + 1
+		return data, nil
+	}
+
+	r.fs.record(r.current, err)
+	r.reader.Close()
+	r.reader = nil
+
+	return r.failover(err)
+}
+
+// failover moves to another replica after the current one returned err.
+// Running out of records is usually the end of the file, so another
+// replica that may have more is only looked for once per retry interval.
+func (r *replicaReader) failover(err error) (reader.DataPacket, error) {
+	if err == io.EOF {
+		now := r.fs.conf.now()
+		if !r.checkedAt.IsZero() && now.Sub(r.checkedAt) < r.fs.conf.retryInterval {
+			return reader.DataPacket{}, io.EOF
+		}
+		r.checkedAt = now
+	}
+
+	if err := r.switchReplica(r.current); err != nil {
+		return reader.DataPacket{}, err
+	}
+
+	return r.Read()
+}
+
+// open opens a Reader at the next index on the first replica that has it.
+func (r *replicaReader) open() error {
+	healthy, unhealthy := r.fs.byHealth()
+
+	var errs []error
+	for _, rep := range append(healthy, unhealthy...) {
+		rd, err := rep.Reader(r.name, r.next)
+		r.fs.record(rep, err)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		r.current, r.reader = rep, rd
+		return nil
+	}
+
+	return readErr(errs)
+}
+
+// switchReplica moves to a replica, other than skip, with a record at the
+// next index. It returns io.EOF if there is none.
+func (r *replicaReader) switchReplica(skip *replica) error {
+	healthy, unhealthy := r.fs.byHealth()
+
+	var errs []error
+	for _, rep := range append(healthy, unhealthy...) {
+		if rep == skip {
+			continue
+		}
+
+		rd, err := rep.Reader(r.name, r.next)
+		if err == nil {
+			var data reader.DataPacket
+			data, err = rd.Read()
+			if err == nil {
+				r.current, r.reader, r.pending = rep, rd, &data
+				return nil
+			}
+			rd.Close()
+		}
+
+		r.fs.record(rep, err)
+		errs = append(errs, err)
+	}
+
+	return readErr(errs)
+}
+
+func (r *replicaReader) Close() {
+	if r.reader != nil {
+		r.reader.Close()
+	}
+}
+
+// readErr returns io.EOF if any replica ran out of records (or, failing
+// that, did not have the file) rather than failing.
+func readErr(errs []error) error {
+	for _, err := range errs {
+		if err == io.EOF {
+			return io.EOF
+		}
+	}
+
+	for _, err := range errs {
+		if errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	if len(errs) == 0 {
+		return io.EOF
+	}
+
+	return errors.Join(errs...)
+}
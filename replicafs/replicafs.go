@@ -0,0 +1,264 @@
+// Package replicafs replicates range files across several FileSystems (e.g.,
+// a remotefs.Client per storage node) so losing one does not lose the
+// ranges stored on it.
+//
+// A record's index is its position in a file, so replicas only agree on
+// indexes if the file's records reach them in the same order. Each file
+// must therefore have a single writer: writes made through one FileSystem
+// are serialized per file, so routers share one (behind a petasos-storage
+// node) rather than each replicating on their own. A Writer that finds
+// records it did not write fails with ErrOtherWriter.
+package replicafs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/router"
+)
+
+// Backend is implemented by localfs.FileSystem and remotefs.Client. It must
+// be safe for concurrent use.
+type Backend interface {
+	List() (file []string, err error)
+	Create(file string) (err error)
+	Writer(name string) (writer router.Writer, err error)
+	Reader(name string, startingIndex uint64) (reader reader.Reader, err error)
+	Len(name string) (length uint64, err error)
+}
+
+// FileSystem creates and writes every file on each of its backends. It
+// implements the router, reader and maintainer FileSystems.
+type FileSystem struct {
+	replicas []*replica
+	conf     fileSystemConfig
+
+	mu    sync.Mutex
+	files map[string]*fileWriter
+}
+
+type fileSystemConfig struct {
+	writeQuorum   int
+	retryInterval time.Duration
+	logger        *slog.Logger
+	now           func() time.Time
+}
+
+type FileSystemOpts func(c *fileSystemConfig)
+
+// New returns a FileSystem replicating to the backends. By default a
+// majority of them must accept each create and write. It returns an error
+// if the write quorum is negative or above the number of backends.
+func New(backends []Backend, opts ...FileSystemOpts) (*FileSystem, error) {
+	conf := fileSystemConfig{
+		retryInterval: 10 * time.Second,
+		logger:        slog.Default(),
+		now:           time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if conf.writeQuorum < 0 || conf.writeQuorum > len(backends) {
+		return nil, fmt.Errorf("write quorum %d is out of range for %d backends", conf.writeQuorum, len(backends))
+	}
+
+	if conf.writeQuorum == 0 {
+		conf.writeQuorum = len(backends)/2 + 1
+	}
+
+	f := &FileSystem{
+		conf:  conf,
+		files: make(map[string]*fileWriter),
+	}
+
+	for i, b := range backends {
+		f.replicas = append(f.replicas, &replica{
+			Backend: b,
+			id:      i,
+		})
+	}
+
+	return f, nil
+}
+
+// ErrOtherWriter is returned by a Writer once a replica has records written
+// through another FileSystem. The Writers that returned it keep failing;
+// Writers opened afterwards start from the records on the replicas.
+var ErrOtherWriter = errors.New("file written through another FileSystem")
+
+// QuorumError is returned when fewer replicas than the quorum succeeded.
+type QuorumError struct {
+	Quorum int
+	Errs   []error
+}
+
+func (e *QuorumError) Error() string {
+	var errs []string
+	for _, err := range e.Errs {
+		errs = append(errs, err.Error())
+	}
+
+	return fmt.Sprintf("quorum of %d not reached: %s", e.Quorum, strings.Join(errs, "; "))
+}
+
+// Unwrap allows errors.Is to match the replicas' errors (e.g.,
+// fs.ErrExist).
+func (e *QuorumError) Unwrap() []error {
+	return e.Errs
+}
+
+// List returns every file on any replica. Enough replicas must answer to
+// see every file created with the write quorum.
+func (f *FileSystem) List() (file []string, err error) {
+	results := make([][]string, len(f.replicas))
+	errs := f.each(f.replicas, func(r *replica) (err error) {
+		results[r.id], err = r.List()
+		return err
+	})
+
+	if err := f.quorum(f.readQuorum(), errs); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, files := range results {
+		for _, name := range files {
+			if !seen[name] {
+				seen[name] = true
+				file = append(file, name)
+			}
+		}
+	}
+	sort.Strings(file)
+
+	return file, nil
+}
+
+// Create creates the file on every replica. It succeeds if the write quorum
+// did.
+func (f *FileSystem) Create(file string) (err error) {
+	errs := f.each(f.replicas, func(r *replica) error {
+		return r.Create(file)
+	})
+
+	return f.quorum(f.conf.writeQuorum, errs)
+}
+
+// Len returns how many records the longest replica has. Enough replicas
+// must answer to see every record written with the write quorum.
+func (f *FileSystem) Len(name string) (length uint64, err error) {
+	lengths := make([]uint64, len(f.replicas))
+	errs := f.each(f.replicas, func(r *replica) (err error) {
+		lengths[r.id], err = r.Len(name)
+		return err
+	})
+
+	if err := f.quorum(f.readQuorum(), errs); err != nil {
+		return 0, err
+	}
+
+	for _, l := range lengths {
+		length = max(length, l)
+	}
+
+	return length, nil
+}
+
+// readQuorum is how many replicas must answer so at least one of them
+// accepted any given write.
+func (f *FileSystem) readQuorum() int {
+	return len(f.replicas) - f.conf.writeQuorum + 1
+}
+
+// each calls fn for every replica concurrently. The errors are indexed by
+// replica id and each failure is recorded against the replica's health.
+func (f *FileSystem) each(replicas []*replica, fn func(r *replica) error) (errs []error) {
+	errs = make([]error, len(f.replicas))
+
+	var wg sync.WaitGroup
+	for _, r := range replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+
+			err := fn(r)
+			f.record(r, err)
+			if err != nil {
+				errs[r.id] = fmt.Errorf("replica %d: %w", r.id, err)
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func (f *FileSystem) quorum(quorum int, errs []error) error {
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	if len(f.replicas)-len(failed) < quorum {
+		return &QuorumError{Quorum: quorum, Errs: failed}
+	}
+
+	return nil
+}
+
+// record marks the replica unhealthy if err means it could not be reached
+// or failed, rather than the file missing or ending.
+func (f *FileSystem) record(r *replica, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case err == nil:
+		r.failedAt = time.Time{}
+	case err == io.EOF, errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrExist):
+	default:
+		if r.failedAt.IsZero() {
+			f.conf.logger.Warn("replica failed", "replica", r.id, "err", err)
+		}
+		r.failedAt = f.conf.now()
+	}
+}
+
+// byHealth returns the healthy replicas followed by those that failed
+// within the retry interval.
+func (f *FileSystem) byHealth() (healthy, unhealthy []*replica) {
+	now := f.conf.now()
+	for _, r := range f.replicas {
+		r.mu.Lock()
+		failedAt := r.failedAt
+		r.mu.Unlock()
+
+		if failedAt.IsZero() || now.Sub(failedAt) >= f.conf.retryInterval {
+			healthy = append(healthy, r)
+			continue
+		}
+		unhealthy = append(unhealthy, r)
+	}
+
+	return healthy, unhealthy
+}
+
+type replica struct {
+	Backend
+	id int
+
+	mu       sync.Mutex
+	failedAt time.Time
+}
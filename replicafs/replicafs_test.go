@@ -0,0 +1,461 @@
+package replicafs_test
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/localfs"
+	"github.com/poy/petasos/reader"
+	"github.com/poy/petasos/replicafs"
+	"github.com/poy/petasos/router"
+)
+
+type TF struct {
+	*testing.T
+
+	backends []*flakyBackend
+	fs       *replicafs.FileSystem
+}
+
+func TestFileSystem(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TF {
+		var (
+			backends []*flakyBackend
+			bs       []replicafs.Backend
+		)
+		for i := 0; i < 3; i++ {
+			local, err := localfs.New(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := &flakyBackend{FileSystem: local}
+			backends = append(backends, b)
+			bs = append(bs, b)
+		}
+
+		fs, err := replicafs.New(bs, replicafs.WithRetryInterval(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return TF{
+			T:        t,
+			backends: backends,
+			fs:       fs,
+		}
+	})
+
+	o.Spec("it rejects a write quorum out of range", func(t TF) {
+		var bs []replicafs.Backend
+		for _, b := range t.backends {
+			bs = append(bs, b)
+		}
+
+		for _, quorum := range []int{-1, 4} {
+			_, err := replicafs.New(bs, replicafs.WithWriteQuorum(quorum))
+			Expect(t, err == nil).To(BeFalse())
+		}
+
+		_, err := replicafs.New(bs, replicafs.WithWriteQuorum(3))
+		Expect(t, err == nil).To(BeTrue())
+	})
+
+	o.Spec("it creates each file on every replica", func(t TF) {
+		Expect(t, t.fs.Create("some-file") == nil).To(BeTrue())
+
+		for _, b := range t.backends {
+			files, err := b.FileSystem.List()
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, files).To(Equal([]string{"some-file"}))
+		}
+	})
+
+	o.Spec("it lists the files on any replica", func(t TF) {
+		t.backends[0].FileSystem.Create("a")
+		t.backends[1].FileSystem.Create("b")
+		t.backends[2].down.Store(true)
+
+		files, err := t.fs.List()
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, files).To(Equal([]string{"a", "b"}))
+	})
+
+	o.Spec("it fails to create a file without the write quorum", func(t TF) {
+		t.backends[0].down.Store(true)
+		t.backends[1].down.Store(true)
+
+		err := t.fs.Create("some-file")
+
+		var quorumErr *replicafs.QuorumError
+		Expect(t, errors.As(err, &quorumErr)).To(BeTrue())
+		Expect(t, quorumErr.Quorum).To(Equal(2))
+	})
+
+	o.Spec("it writes to every replica", func(t TF) {
+		t.fs.Create("some-file")
+		write(t, t.fs, "some-file", "a", "b")
+
+		for _, b := range t.backends {
+			Expect(t, readAll(t, b.FileSystem, "some-file")).To(Equal([]string{"a", "b"}))
+		}
+	})
+
+	o.Spec("it writes while a replica is down", func(t TF) {
+		t.fs.Create("some-file")
+		t.backends[0].down.Store(true)
+
+		write(t, t.fs, "some-file", "a")
+		Expect(t, readAll(t, t.backends[1].FileSystem, "some-file")).To(Equal([]string{"a"}))
+	})
+
+	o.Spec("it fails to write without the write quorum", func(t TF) {
+		t.fs.Create("some-file")
+
+		w, err := t.fs.Writer("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		defer w.Close()
+
+		t.backends[0].down.Store(true)
+		t.backends[1].down.Store(true)
+
+		Expect(t, w.Write([]byte("a")) == nil).To(BeFalse())
+	})
+
+	o.Spec("it catches up a replica that missed writes", func(t TF) {
+		t.fs.Create("some-file")
+
+		w, err := t.fs.Writer("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		defer w.Close()
+
+		Expect(t, w.Write([]byte("a")) == nil).To(BeTrue())
+
+		t.backends[0].down.Store(true)
+		Expect(t, w.Write([]byte("b")) == nil).To(BeTrue())
+		t.backends[0].down.Store(false)
+
+		Expect(t, w.Write([]byte("c")) == nil).To(BeTrue())
+		Expect(t, readAll(t, t.backends[0].FileSystem, "some-file")).To(Equal([]string{"a", "b", "c"}))
+	})
+
+	o.Spec("it keeps writing while a replica catches up", func(t TF) {
+		t.fs.Create("some-file")
+
+		w, err := t.fs.Writer("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		defer w.Close()
+
+		Expect(t, w.Write([]byte("a")) == nil).To(BeTrue())
+
+		t.backends[0].down.Store(true)
+		Expect(t, w.Write([]byte("b")) == nil).To(BeTrue())
+		t.backends[0].down.Store(false)
+
+		gate := make(chan struct{})
+		t.backends[0].gate = gate
+
+		done := make(chan error, 1)
+		go func() {
+			done <- w.Write([]byte("c"))
+		}()
+
+		for !t.backends[0].waiting.Load() {
+			time.Sleep(time.Millisecond)
+		}
+
+		other, err := t.fs.Writer("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		defer other.Close()
+
+		written := make(chan error, 1)
+		go func() {
+			written <- other.Write([]byte("d"))
+		}()
+
+		select {
+		case err := <-written:
+			Expect(t, err == nil).To(BeTrue())
+		case <-time.After(time.Second):
+			close(gate)
+			t.Fatal("write blocked by the catch up")
+		}
+		close(gate)
+
+		Expect(t, <-done == nil).To(BeTrue())
+		Expect(t, readAll(t, t.backends[0].FileSystem, "some-file")).To(Equal([]string{"a", "b", "d", "c"}))
+		Expect(t, readAll(t, t.backends[1].FileSystem, "some-file")).To(Equal([]string{"a", "b", "d", "c"}))
+	})
+
+	o.Spec("it stops writing a file another FileSystem writes to", func(t TF) {
+		var bs []replicafs.Backend
+		for _, b := range t.backends {
+			bs = append(bs, b)
+		}
+		other, err := replicafs.New(bs, replicafs.WithRetryInterval(0))
+		Expect(t, err == nil).To(BeTrue())
+
+		t.fs.Create("some-file")
+
+		w, err := t.fs.Writer("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		defer w.Close()
+
+		otherW, err := other.Writer("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		defer otherW.Close()
+
+		Expect(t, w.Write([]byte("a")) == nil).To(BeTrue())
+
+		err = otherW.Write([]byte("b"))
+		Expect(t, errors.Is(err, replicafs.ErrOtherWriter)).To(BeTrue())
+		Expect(t, w.Write([]byte("c")) == nil).To(BeTrue())
+
+		// Reopened, it starts from the records on the replicas.
+		otherW, err = other.Writer("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		defer otherW.Close()
+		Expect(t, otherW.Write([]byte("d")) == nil).To(BeTrue())
+
+		err = w.Write([]byte("e"))
+		Expect(t, errors.Is(err, replicafs.ErrOtherWriter)).To(BeTrue())
+
+		for _, b := range t.backends {
+			Expect(t, readAll(t, b.FileSystem, "some-file")).To(Equal([]string{"a", "c", "d"}))
+		}
+	})
+
+	o.Spec("it measures each replica without reading it when a file is reopened", func(t TF) {
+		t.fs.Create("some-file")
+		write(t, t.fs, "some-file", "a", "b", "c")
+		for _, b := range t.backends {
+			b.readers.Store(0)
+		}
+
+		w, err := t.fs.Writer("some-file")
+		Expect(t, err == nil).To(BeTrue())
+		defer w.Close()
+
+		for _, b := range t.backends {
+			Expect(t, b.readers.Load()).To(Equal(int64(0)))
+		}
+
+		Expect(t, w.Write([]byte("d")) == nil).To(BeTrue())
+		Expect(t, readAll(t, t.backends[0].FileSystem, "some-file")).To(Equal([]string{"a", "b", "c", "d"}))
+	})
+
+	o.Spec("it creates the file on a replica that missed it", func(t TF) {
+		t.backends[0].down.Store(true)
+		t.fs.Create("some-file")
+		t.backends[0].down.Store(false)
+
+		write(t, t.fs, "some-file", "a")
+		Expect(t, readAll(t, t.backends[0].FileSystem, "some-file")).To(Equal([]string{"a"}))
+	})
+
+	o.Spec("it reads from another replica when one is down", func(t TF) {
+		t.fs.Create("some-file")
+		write(t, t.fs, "some-file", "a", "b")
+		t.backends[0].down.Store(true)
+
+		Expect(t, readAll(t, t.fs, "some-file")).To(Equal([]string{"a", "b"}))
+	})
+
+	o.Spec("it continues reading from a replica with more records", func(t TF) {
+		t.fs.Create("some-file")
+		write(t, t.fs, "some-file", "a")
+
+		t.backends[0].down.Store(true)
+		write(t, t.fs, "some-file", "b", "c")
+		t.backends[0].down.Store(false)
+
+		r, err := t.fs.Reader("some-file", 0)
+		Expect(t, err == nil).To(BeTrue())
+		defer r.Close()
+
+		for i, payload := range []string{"a", "b", "c"} {
+			data, err := r.Read()
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, string(data.Payload)).To(Equal(payload))
+			Expect(t, data.Index).To(Equal(uint64(i)))
+		}
+
+		_, err = r.Read()
+		Expect(t, err).To(Equal(io.EOF))
+	})
+
+	o.Spec("it only checks the other replicas for more records once per retry interval", func(t TF) {
+		now := time.Unix(0, 0)
+		var bs []replicafs.Backend
+		for _, b := range t.backends {
+			bs = append(bs, b)
+		}
+		fs, err := replicafs.New(bs,
+			replicafs.WithRetryInterval(time.Minute),
+			replicafs.WithClock(func() time.Time { return now }),
+		)
+		Expect(t, err == nil).To(BeTrue())
+
+		fs.Create("some-file")
+		write(t, fs, "some-file", "a")
+		for _, b := range t.backends {
+			b.readers.Store(0)
+		}
+
+		r, err := fs.Reader("some-file", 0)
+		Expect(t, err == nil).To(BeTrue())
+		defer r.Close()
+
+		_, err = r.Read()
+		Expect(t, err == nil).To(BeTrue())
+
+		for i := 0; i < 5; i++ {
+			_, err = r.Read()
+			Expect(t, err).To(Equal(io.EOF))
+		}
+		Expect(t, t.backends[0].readers.Load()).To(Equal(int64(5)))
+		Expect(t, t.backends[1].readers.Load()).To(Equal(int64(1)))
+		Expect(t, t.backends[2].readers.Load()).To(Equal(int64(1)))
+
+		now = now.Add(time.Minute)
+		_, err = r.Read()
+		Expect(t, err).To(Equal(io.EOF))
+		Expect(t, t.backends[1].readers.Load()).To(Equal(int64(2)))
+		Expect(t, t.backends[2].readers.Load()).To(Equal(int64(2)))
+	})
+
+	o.Spec("it returns io.EOF when no replica has the starting index", func(t TF) {
+		t.fs.Create("some-file")
+		write(t, t.fs, "some-file", "a")
+
+		_, err := t.fs.Reader("some-file", 2)
+		Expect(t, err).To(Equal(io.EOF))
+	})
+}
+
+func write(t TF, fs router.FileSystem, name string, payloads ...string) {
+	t.Helper()
+
+	w, err := fs.Writer(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for _, p := range payloads {
+		if err := w.Write([]byte(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readAll(t TF, fs reader.FileSystem, name string) (payloads []string) {
+	t.Helper()
+
+	r, err := fs.Reader(name, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for {
+		data, err := r.Read()
+		if err == io.EOF {
+			return payloads
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		payloads = append(payloads, string(data.Payload))
+	}
+}
+
+var errDown = errors.New("down")
+
+// flakyBackend fails every call while it is down. Writers opened while it
+// has a gate wait for the gate to close before each write.
+type flakyBackend struct {
+	*localfs.FileSystem
+	down    atomic.Bool
+	readers atomic.Int64
+	gate    chan struct{}
+	waiting atomic.Bool
+}
+
+func (b *flakyBackend) List() ([]string, error) {
+	if b.down.Load() {
+		return nil, errDown
+	}
+
+	return b.FileSystem.List()
+}
+
+func (b *flakyBackend) Create(file string) error {
+	if b.down.Load() {
+		return errDown
+	}
+
+	return b.FileSystem.Create(file)
+}
+
+func (b *flakyBackend) Writer(name string) (router.Writer, error) {
+	if b.down.Load() {
+		return nil, errDown
+	}
+
+	w, err := b.FileSystem.Writer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &flakyWriter{Writer: w, down: &b.down, gate: b.gate, waiting: &b.waiting}, nil
+}
+
+func (b *flakyBackend) Len(name string) (uint64, error) {
+	if b.down.Load() {
+		return 0, errDown
+	}
+
+	return b.FileSystem.Len(name)
+}
+
+func (b *flakyBackend) Reader(name string, startingIndex uint64) (reader.Reader, error) {
+	b.readers.Add(1)
+	if b.down.Load() {
+		return nil, errDown
+	}
+
+	return b.FileSystem.Reader(name, startingIndex)
+}
+
+type flakyWriter struct {
+	router.Writer
+	down    *atomic.Bool
+	gate    chan struct{}
+	waiting *atomic.Bool
+}
+
+func (w *flakyWriter) Write(data []byte) error {
+	if w.gate != nil {
+		w.waiting.Store(true)
+		<-w.gate
+	}
+
+	if w.down.Load() {
+		return errDown
+	}
+
+	return w.Writer.Write(data)
+}
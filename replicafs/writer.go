@@ -0,0 +1,430 @@
+package replicafs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/poy/petasos/router"
+)
+
+// Writer returns a Writer for an existing file. Every Writer for a file
+// shares the same replicas, each of which is first caught up with the
+// longest replica by copying the records it is missing. It fails unless
+// the write quorum could be caught up.
+//
+// While writing, the replicas are checked for records written through
+// another FileSystem at most once per retry interval (see ErrOtherWriter).
+func (f *FileSystem) Writer(name string) (writer router.Writer, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fw, ok := f.files[name]
+	if !ok {
+		fw, err = f.openFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f.files[name] = fw
+	}
+	fw.refs++
+
+	return &writerHandle{fw: fw}, nil
+}
+
+func (f *FileSystem) openFile(name string) (*fileWriter, error) {
+	fw := &fileWriter{
+		fs:         f,
+		name:       name,
+		writers:    make(map[*replica]router.Writer),
+		lastJoin:   f.conf.now(),
+		verifiedAt: f.conf.now(),
+	}
+
+	healthy, _ := f.byHealth()
+	errs := fw.join(healthy)
+
+	if len(fw.writers) < f.conf.writeQuorum {
+		fw.close()
+		return nil, &QuorumError{Quorum: f.conf.writeQuorum, Errs: errs}
+	}
+
+	return fw, nil
+}
+
+// release closes the file's writers once every Writer has been closed.
+func (f *FileSystem) release(fw *fileWriter) {
+	f.mu.Lock()
+	fw.refs--
+	last := fw.refs == 0
+	if last && f.files[fw.name] == fw {
+		delete(f.files, fw.name)
+	}
+	f.mu.Unlock()
+
+	if last {
+		fw.close()
+	}
+}
+
+// detach stops new Writers from sharing fw so they open the file again.
+func (f *FileSystem) detach(fw *fileWriter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.files[fw.name] == fw {
+		delete(f.files, fw.name)
+	}
+}
+
+// fileWriter writes a file to every replica that has all of its records.
+type fileWriter struct {
+	fs   *FileSystem
+	name string
+	refs int
+
+	mu         sync.Mutex
+	writers    map[*replica]router.Writer
+	length     uint64
+	lastJoin   time.Time
+	verifiedAt time.Time
+	joining    bool
+	broken     bool
+}
+
+// write writes the record to every replica. A replica that fails is dropped
+// until it is caught up again. Once fewer than the write quorum remain, fw
+// fails every write.
+func (fw *fileWriter) write(data []byte) error {
+	fw.rejoin()
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.broken {
+		return fmt.Errorf("too few replicas of %s remain", fw.name)
+	}
+
+	if err := fw.verify(); err != nil {
+		return err
+	}
+
+	live := make([]*replica, 0, len(fw.writers))
+	for r := range fw.writers {
+		live = append(live, r)
+	}
+
+	errs := fw.fs.each(live, func(r *replica) error {
+		return fw.writers[r].Write(data)
+	})
+
+	var failed []error
+	for _, r := range live {
+		if err := errs[r.id]; err != nil {
+			failed = append(failed, err)
+			fw.writers[r].Close()
+			delete(fw.writers, r)
+		}
+	}
+
+	if len(fw.writers) < fw.fs.conf.writeQuorum {
+		fw.broken = true
+		fw.fs.detach(fw)
+		return &QuorumError{Quorum: fw.fs.conf.writeQuorum, Errs: failed}
+	}
+	fw.length++
+
+	return nil
+}
+
+// verify checks, at most once per retry interval, that no live replica
+// has records fw did not write. Those were written through another
+// FileSystem and may have reached the replicas in another order, so fw
+// stops writing. fw.mu must be held.
+func (fw *fileWriter) verify() error {
+	now := fw.fs.conf.now()
+	if now.Sub(fw.verifiedAt) < fw.fs.conf.retryInterval {
+		return nil
+	}
+	fw.verifiedAt = now
+
+	live := make([]*replica, 0, len(fw.writers))
+	for r := range fw.writers {
+		live = append(live, r)
+	}
+
+	// A replica that fails to answer is dropped by the write.
+	lengths := make([]uint64, len(fw.fs.replicas))
+	errs := fw.fs.each(live, func(r *replica) (err error) {
+		lengths[r.id], err = r.Len(fw.name)
+		return err
+	})
+
+	for _, r := range live {
+		if errs[r.id] != nil || lengths[r.id] <= fw.length {
+			continue
+		}
+
+		fw.broken = true
+		fw.fs.detach(fw)
+		fw.fs.conf.logger.Error("file written through another FileSystem", "file", fw.name, "replica", r.id)
+
+		return fmt.Errorf("replica %d has %d records of %s, more than the %d written: %w", r.id, lengths[r.id], fw.name, fw.length, ErrOtherWriter)
+	}
+
+	return nil
+}
+
+// rejoin tries to catch up the healthy replicas without a writer, at most
+// once per retry interval. The records they are missing are copied from a
+// live replica without holding fw.mu so that writes continue meanwhile.
+// Only the records written during that copy are copied while holding it.
+func (fw *fileWriter) rejoin() {
+	source, candidates, target := fw.startRejoin()
+	if len(candidates) == 0 {
+		return
+	}
+
+	writers := make([]router.Writer, len(fw.fs.replicas))
+	lengths := make([]uint64, len(fw.fs.replicas))
+
+	copied := fw.fs.each(candidates, func(r *replica) (err error) {
+		writers[r.id], err = openWriter(r, fw.name)
+		if err != nil {
+			return err
+		}
+
+		lengths[r.id], err = r.Len(fw.name)
+		if err != nil {
+			return err
+		}
+
+		if lengths[r.id] > target {
+			return fmt.Errorf("has %d records, more than the %d of replica %d", lengths[r.id], target, source.id)
+		}
+
+		return catchUp(source, writers[r.id], fw.name, lengths[r.id], target)
+	})
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.joining = false
+
+	// Nothing is written while fw.mu is held, so fw.length is final.
+	end := fw.length
+	var endErr error
+	if _, live := fw.writers[source]; !live || fw.broken {
+		endErr = fmt.Errorf("replica %d is no longer written to", source.id)
+	}
+
+	for _, r := range candidates {
+		err := errors.Join(copied[r.id], endErr)
+		if err == nil {
+			err = catchUp(source, writers[r.id], fw.name, target, end)
+			fw.fs.record(r, err)
+		}
+
+		if err == nil {
+			fw.writers[r] = writers[r.id]
+			continue
+		}
+
+		if writers[r.id] != nil {
+			writers[r.id].Close()
+		}
+		fw.fs.conf.logger.Warn("failed to catch up replica", "file", fw.name, "err", err)
+	}
+}
+
+// startRejoin returns the healthy replicas without a writer, a live
+// replica to copy their missing records from and how many records it has.
+// It returns no candidates if a rejoin is already running or the retry
+// interval has not passed.
+func (fw *fileWriter) startRejoin() (source *replica, candidates []*replica, length uint64) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.broken || fw.joining || len(fw.writers) == len(fw.fs.replicas) {
+		return nil, nil, 0
+	}
+
+	now := fw.fs.conf.now()
+	if now.Sub(fw.lastJoin) < fw.fs.conf.retryInterval {
+		return nil, nil, 0
+	}
+	fw.lastJoin = now
+
+	healthy, _ := fw.fs.byHealth()
+	for _, r := range healthy {
+		if _, ok := fw.writers[r]; !ok {
+			candidates = append(candidates, r)
+		}
+	}
+
+	for r := range fw.writers {
+		if source == nil || r.id < source.id {
+			source = r
+		}
+	}
+
+	if len(candidates) > 0 {
+		fw.joining = true
+	}
+
+	return source, candidates, fw.length
+}
+
+// join opens a writer on each candidate and copies the records it is
+// missing from the longest replica. The candidates that could not be
+// caught up are left out and their errors returned.
+func (fw *fileWriter) join(candidates []*replica) (errs []error) {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	writers := make([]router.Writer, len(fw.fs.replicas))
+	lengths := make([]uint64, len(fw.fs.replicas))
+
+	everyone := append([]*replica(nil), candidates...)
+	for r := range fw.writers {
+		everyone = append(everyone, r)
+	}
+
+	measured := fw.fs.each(everyone, func(r *replica) (err error) {
+		if _, live := fw.writers[r]; !live {
+			writers[r.id], err = openWriter(r, fw.name)
+			if err != nil {
+				return err
+			}
+		}
+
+		lengths[r.id], err = r.Len(fw.name)
+		return err
+	})
+
+	// The live replicas have every record written so far. Without any,
+	// the longest replica has.
+	var (
+		source   *replica
+		target   uint64
+		fromLive bool
+	)
+	for _, r := range everyone {
+		if measured[r.id] != nil {
+			continue
+		}
+
+		_, live := fw.writers[r]
+		if source == nil || (live && !fromLive) || (live == fromLive && lengths[r.id] > target) {
+			source, target, fromLive = r, lengths[r.id], live
+		}
+	}
+
+	var ready []*replica
+	for _, r := range candidates {
+		if measured[r.id] == nil {
+			ready = append(ready, r)
+		}
+	}
+
+	caughtUp := fw.fs.each(ready, func(r *replica) error {
+		if lengths[r.id] > target {
+			return fmt.Errorf("has %d records, more than the %d of replica %d", lengths[r.id], target, source.id)
+		}
+
+		return catchUp(source, writers[r.id], fw.name, lengths[r.id], target)
+	})
+
+	if source != nil {
+		fw.length = target
+	}
+
+	for _, r := range candidates {
+		err := errors.Join(measured[r.id], caughtUp[r.id])
+		if err == nil {
+			fw.writers[r] = writers[r.id]
+			continue
+		}
+
+		if writers[r.id] != nil {
+			writers[r.id].Close()
+		}
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// close closes every writer.
+func (fw *fileWriter) close() {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	for r, w := range fw.writers {
+		w.Close()
+		delete(fw.writers, r)
+	}
+}
+
+// openWriter opens a writer on the replica, creating the file if the
+// replica missed its creation.
+func openWriter(r *replica, name string) (router.Writer, error) {
+	w, err := r.Writer(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := r.Create(name); err != nil && !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		w, err = r.Writer(name)
+	}
+
+	return w, err
+}
+
+// catchUp copies the records from index from up to to from the source.
+func catchUp(source *replica, w router.Writer, name string, from, to uint64) error {
+	if from == to {
+		return nil
+	}
+
+	rd, err := source.Reader(name, from)
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+
+	for i := from; i < to; i++ {
+		data, err := rd.Read()
+		if err == io.EOF {
+			return fmt.Errorf("replica %d ended at record %d of %d", source.id, i, to)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := w.Write(data.Payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writerHandle is the router.Writer for a shared fileWriter.
+type writerHandle struct {
+	fw   *fileWriter
+	once sync.Once
+}
+
+func (h *writerHandle) Write(data []byte) (err error) {
+	return h.fw.write(data)
+}
+
+func (h *writerHandle) Close() {
+	h.once.Do(func() {
+		h.fw.fs.release(h.fw)
+	})
+}
@@ -0,0 +1,37 @@
+package router
+
+// FileCounter adapts a Counter to be keyed by file name instead of
+// RangeName. It can be handed directly to metrics.NewAggregator.
+type FileCounter struct {
+	counter *Counter
+	codec   RangeNameCodec
+}
+
+func NewFileCounter(counter *Counter, codec RangeNameCodec) *FileCounter {
+	return &FileCounter{
+		counter: counter,
+		codec:   codec,
+	}
+}
+
+// Metrics returns the metrics for the given file. Files that can not be
+// decoded have no metrics.
+func (f *FileCounter) Metrics(file string) (metric Metric) {
+	rn, err := f.codec.Decode(file)
+	if err != nil {
+		return Metric{}
+	}
+
+	return f.counter.Metrics(rn)
+}
+
+// BulkMetrics returns the metrics for every range keyed by file name.
+func (f *FileCounter) BulkMetrics() (metrics map[string]Metric) {
+	all := f.counter.All()
+
+	metrics = make(map[string]Metric, len(all))
+	for rn, m := range all {
+		metrics[f.codec.Encode(rn)] = m
+	}
+	return metrics
+}
@@ -0,0 +1,56 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/router"
+)
+
+type TFC struct {
+	*testing.T
+
+	counter     *router.Counter
+	fileCounter *router.FileCounter
+}
+
+func TestFileCounter(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TFC {
+		counter := router.NewCounter()
+
+		return TFC{
+			T:           t,
+			counter:     counter,
+			fileCounter: router.NewFileCounter(counter, router.JSONCodec{}),
+		}
+	})
+
+	o.Spec("it reports the metrics for the file", func(t TFC) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 5)
+
+		metric := t.fileCounter.Metrics(buildRangeName(1, 2, 3))
+		Expect(t, metric.WriteCount).To(Equal(uint64(1)))
+		Expect(t, metric.ByteCount).To(Equal(uint64(5)))
+	})
+
+	o.Spec("it reports nothing for a non-petasos file", func(t TFC) {
+		metric := t.fileCounter.Metrics("some-file")
+		Expect(t, metric).To(Equal(router.Metric{}))
+	})
+
+	o.Spec("it reports every range by file", func(t TFC) {
+		t.counter.IncSuccess(router.RangeName{Low: 1, High: 2, Term: 3}, 5)
+		t.counter.IncFailure(router.RangeName{Low: 3, High: 4, Term: 5})
+
+		metrics := t.fileCounter.BulkMetrics()
+		Expect(t, metrics).To(HaveLen(2))
+		Expect(t, metrics[buildRangeName(1, 2, 3)].WriteCount).To(Equal(uint64(1)))
+		Expect(t, metrics[buildRangeName(3, 4, 5)].ErrCount).To(Equal(uint64(1)))
+	})
+}
@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/poy/petasos/router/grpc/routerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client writes to a router serving a Server.
+type Client struct {
+	dialOpts []grpc.DialOption
+	timeout  time.Duration
+
+	conn   *grpc.ClientConn
+	client routerpb.RouterClient
+}
+
+type ClientOpts func(c *Client)
+
+// WithDialOptions sets the options used to connect to the router. It
+// defaults to an insecure connection.
+func WithDialOptions(opts ...grpc.DialOption) func(c *Client) {
+	return func(c *Client) {
+		c.dialOpts = opts
+	}
+}
+
+// WithTimeout sets the timeout for each request. It defaults to 5 seconds.
+func WithTimeout(timeout time.Duration) func(c *Client) {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+func NewClient(addr string, opts ...ClientOpts) (*Client, error) {
+	c := &Client{
+		dialOpts: []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		},
+		timeout: 5 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	conn, err := grpc.NewClient(addr, c.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+	c.client = routerpb.NewRouterClient(conn)
+
+	return c, nil
+}
+
+// Write sends the payloads to the router in a single request.
+func (c *Client) Write(payloads ...[]byte) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	_, err = c.client.Write(ctx, &routerpb.WriteRequest{Payloads: payloads})
+	return err
+}
+
+func (c *Client) Close() {
+	c.conn.Close()
+}
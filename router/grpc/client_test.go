@@ -0,0 +1,63 @@
+package grpc_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	routergrpc "github.com/poy/petasos/router/grpc"
+	"github.com/poy/petasos/router/grpc/routerpb"
+	"google.golang.org/grpc"
+)
+
+type TC struct {
+	*testing.T
+
+	writer *stubWriter
+	server *grpc.Server
+	client *routergrpc.Client
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TC {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		writer := &stubWriter{}
+		server := grpc.NewServer()
+		routerpb.RegisterRouterServer(server, routergrpc.NewServer(writer))
+		go server.Serve(lis)
+
+		client, err := routergrpc.NewClient(lis.Addr().String(), routergrpc.WithTimeout(time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return TC{
+			T:      t,
+			writer: writer,
+			server: server,
+			client: client,
+		}
+	})
+
+	o.AfterEach(func(t TC) {
+		t.client.Close()
+		t.server.Stop()
+	})
+
+	o.Spec("it writes the payloads to the router", func(t TC) {
+		err := t.client.Write([]byte("a"), []byte("b"))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, t.writer.written()).To(Equal([][]byte{[]byte("a"), []byte("b")}))
+	})
+}
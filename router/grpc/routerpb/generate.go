@@ -0,0 +1,4 @@
+// Package routerpb contains the generated gRPC service for writes.
+package routerpb
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. router.proto
@@ -0,0 +1,41 @@
+// Package grpc accepts writes for a router over gRPC.
+package grpc
+
+import (
+	"context"
+
+	"github.com/poy/petasos/router/grpc/routerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Writer is implemented by router.Router. It must be safe for concurrent
+// use.
+type Writer interface {
+	WriteContext(ctx context.Context, data []byte) (err error)
+}
+
+// Server routes the payloads it receives with a Writer. Register it with
+// routerpb.RegisterRouterServer.
+type Server struct {
+	routerpb.UnimplementedRouterServer
+
+	writer Writer
+}
+
+func NewServer(writer Writer) *Server {
+	return &Server{
+		writer: writer,
+	}
+}
+
+func (s *Server) Write(ctx context.Context, req *routerpb.WriteRequest) (*routerpb.WriteResponse, error) {
+	payloads := req.GetPayloads()
+	for i, p := range payloads {
+		if err := s.writer.WriteContext(ctx, p); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "wrote %d of %d payloads: %s", i, len(payloads), err)
+		}
+	}
+
+	return &routerpb.WriteResponse{}, nil
+}
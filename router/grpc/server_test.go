@@ -0,0 +1,81 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	routergrpc "github.com/poy/petasos/router/grpc"
+	"github.com/poy/petasos/router/grpc/routerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type TS struct {
+	*testing.T
+
+	writer *stubWriter
+	server *routergrpc.Server
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TS {
+		writer := &stubWriter{}
+
+		return TS{
+			T:      t,
+			writer: writer,
+			server: routergrpc.NewServer(writer),
+		}
+	})
+
+	o.Spec("it writes each payload in order", func(t TS) {
+		_, err := t.server.Write(context.Background(), &routerpb.WriteRequest{
+			Payloads: [][]byte{[]byte("a"), []byte("b")},
+		})
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, t.writer.written()).To(Equal([][]byte{[]byte("a"), []byte("b")}))
+	})
+
+	o.Spec("it returns Unavailable when a write fails", func(t TS) {
+		t.writer.err = fmt.Errorf("some-error")
+
+		_, err := t.server.Write(context.Background(), &routerpb.WriteRequest{
+			Payloads: [][]byte{[]byte("a")},
+		})
+		Expect(t, status.Code(err)).To(Equal(codes.Unavailable))
+	})
+}
+
+type stubWriter struct {
+	mu   sync.Mutex
+	data [][]byte
+	err  error
+}
+
+func (s *stubWriter) WriteContext(ctx context.Context, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	s.data = append(s.data, data)
+	return nil
+}
+
+func (s *stubWriter) written() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data
+}
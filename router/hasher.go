@@ -0,0 +1,12 @@
+package router
+
+import "hash/fnv"
+
+// FNVHasher hashes data with 64-bit FNV-1a.
+type FNVHasher struct{}
+
+func (FNVHasher) Hash(data []byte) (hash uint64, err error) {
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64(), nil
+}
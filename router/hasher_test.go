@@ -0,0 +1,22 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/router"
+)
+
+func TestFNVHasher(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it hashes with FNV-1a", func(t *testing.T) {
+		hash, err := router.FNVHasher{}.Hash([]byte("a"))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, hash).To(Equal(uint64(0xaf63dc4c8601ec8c)))
+	})
+}
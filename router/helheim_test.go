@@ -5,7 +5,11 @@
 
 package router_test
 
-import "github.com/poy/petasos/router"
+import (
+	"time"
+
+	"github.com/poy/petasos/router"
+)
 
 type mockWriter struct {
 	WriteCalled chan bool
@@ -100,27 +104,43 @@ func (m *mockHasher) Hash(data []byte) (hash uint64, err error) {
 type mockMetricsCounter struct {
 	IncSuccessCalled chan bool
 	IncSuccessInput  struct {
-		Name chan router.RangeName
+		Name  chan router.RangeName
+		Bytes chan uint64
 	}
 	IncFailureCalled chan bool
 	IncFailureInput  struct {
 		Name chan router.RangeName
 	}
+	RecordLatencyCalled chan bool
+	RecordLatencyInput  struct {
+		Name    chan router.RangeName
+		Latency chan time.Duration
+	}
 }
 
 func newMockMetricsCounter() *mockMetricsCounter {
 	m := &mockMetricsCounter{}
 	m.IncSuccessCalled = make(chan bool, 100)
 	m.IncSuccessInput.Name = make(chan router.RangeName, 100)
+	m.IncSuccessInput.Bytes = make(chan uint64, 100)
 	m.IncFailureCalled = make(chan bool, 100)
 	m.IncFailureInput.Name = make(chan router.RangeName, 100)
+	m.RecordLatencyCalled = make(chan bool, 100)
+	m.RecordLatencyInput.Name = make(chan router.RangeName, 100)
+	m.RecordLatencyInput.Latency = make(chan time.Duration, 100)
 	return m
 }
-func (m *mockMetricsCounter) IncSuccess(name router.RangeName) {
+func (m *mockMetricsCounter) IncSuccess(name router.RangeName, bytes uint64) {
 	m.IncSuccessCalled <- true
 	m.IncSuccessInput.Name <- name
+	m.IncSuccessInput.Bytes <- bytes
 }
 func (m *mockMetricsCounter) IncFailure(name router.RangeName) {
 	m.IncFailureCalled <- true
 	m.IncFailureInput.Name <- name
 }
+func (m *mockMetricsCounter) RecordLatency(name router.RangeName, latency time.Duration) {
+	m.RecordLatencyCalled <- true
+	m.RecordLatencyInput.Name <- name
+	m.RecordLatencyInput.Latency <- latency
+}
@@ -0,0 +1,123 @@
+package router
+
+import (
+	"math/bits"
+	"time"
+)
+
+const histogramBuckets = 32
+
+// Histogram counts write latencies in exponentially sized buckets. Bucket i
+// holds latencies below 2^i microseconds (the last bucket holds everything
+// else). Histograms from different routers can be merged by adding their
+// buckets.
+type Histogram struct {
+	Buckets [histogramBuckets]uint64
+	Max     time.Duration
+}
+
+// Observe records a single latency.
+func (h *Histogram) Observe(d time.Duration) {
+	h.Buckets[bucketFor(d)]++
+	if d > h.Max {
+		h.Max = d
+	}
+}
+
+// Count returns the number of recorded latencies.
+func (h Histogram) Count() (count uint64) {
+	for _, c := range h.Buckets {
+		count += c
+	}
+	return count
+}
+
+// Merge returns the combination of both histograms.
+func (h Histogram) Merge(o Histogram) Histogram {
+	for i, c := range o.Buckets {
+		h.Buckets[i] += c
+	}
+
+	if o.Max > h.Max {
+		h.Max = o.Max
+	}
+	return h
+}
+
+// Sub returns the latencies recorded in h but not in prev. Max can not be
+// subtracted exactly, so it is lowered to the bound of the highest bucket
+// left (it stays as is if that is the last bucket).
+func (h Histogram) Sub(prev Histogram) Histogram {
+	for i, c := range prev.Buckets {
+		if c > h.Buckets[i] {
+			h.Buckets[i] = 0
+			continue
+		}
+		h.Buckets[i] -= c
+	}
+
+	highest := -1
+	for i, c := range h.Buckets {
+		if c > 0 {
+			highest = i
+		}
+	}
+
+	switch {
+	case highest < 0:
+		h.Max = 0
+	case highest < histogramBuckets-1:
+		upper := time.Duration(1<<uint(highest)) * time.Microsecond
+		if upper < h.Max {
+			h.Max = upper
+		}
+	}
+	return h
+}
+
+// Quantile returns an upper bound for the given quantile (0 <= q <= 1). It
+// never reports more than Max.
+func (h Histogram) Quantile(q float64) time.Duration {
+	count := h.Count()
+	if count == 0 {
+		return 0
+	}
+
+	rank := uint64(q*float64(count) + 0.5)
+	if rank == 0 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i, c := range h.Buckets {
+		seen += c
+		if seen < rank {
+			continue
+		}
+
+		upper := time.Duration(1<<uint(i)) * time.Microsecond
+		if i == histogramBuckets-1 || upper > h.Max {
+			return h.Max
+		}
+		return upper
+	}
+
+	return h.Max
+}
+
+func (h Histogram) P50() time.Duration {
+	return h.Quantile(0.5)
+}
+
+func (h Histogram) P99() time.Duration {
+	return h.Quantile(0.99)
+}
+
+func bucketFor(d time.Duration) int {
+	us := uint64(d / time.Microsecond)
+	i := bits.Len64(us)
+	if i >= histogramBuckets {
+		return histogramBuckets - 1
+	}
+	return i
+}
@@ -0,0 +1,80 @@
+package router_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/router"
+)
+
+type TH struct {
+	*testing.T
+
+	h router.Histogram
+}
+
+func TestHistogram(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TH {
+		var h router.Histogram
+		for i := 0; i < 98; i++ {
+			h.Observe(100 * time.Microsecond)
+		}
+		h.Observe(50 * time.Millisecond)
+		h.Observe(time.Second)
+
+		return TH{
+			T: t,
+			h: h,
+		}
+	})
+
+	o.Spec("it reports the count", func(t TH) {
+		Expect(t, t.h.Count()).To(Equal(uint64(100)))
+	})
+
+	o.Spec("it reports percentiles", func(t TH) {
+		Expect(t, t.h.P50()).To(Equal(128 * time.Microsecond))
+		Expect(t, t.h.P99()).To(Equal(65536 * time.Microsecond))
+		Expect(t, t.h.Quantile(1)).To(Equal(time.Second))
+	})
+
+	o.Spec("it merges histograms", func(t TH) {
+		var other router.Histogram
+		other.Observe(2 * time.Second)
+
+		merged := t.h.Merge(other)
+		Expect(t, merged.Count()).To(Equal(uint64(101)))
+		Expect(t, merged.Max).To(Equal(2 * time.Second))
+	})
+
+	o.Spec("it subtracts histograms", func(t TH) {
+		var prev router.Histogram
+		prev.Observe(100 * time.Microsecond)
+
+		Expect(t, t.h.Sub(prev).Count()).To(Equal(uint64(99)))
+	})
+
+	o.Spec("it lowers Max to the latencies left after subtracting", func(t TH) {
+		var prev router.Histogram
+		prev.Observe(50 * time.Millisecond)
+		prev.Observe(time.Second)
+
+		delta := t.h.Sub(prev)
+		Expect(t, delta.Max).To(Equal(128 * time.Microsecond))
+		Expect(t, delta.P99()).To(Equal(128 * time.Microsecond))
+
+		Expect(t, t.h.Sub(t.h).Max).To(Equal(time.Duration(0)))
+	})
+
+	o.Spec("it reports 0 for an empty histogram", func(t TH) {
+		var h router.Histogram
+		Expect(t, h.P99()).To(Equal(time.Duration(0)))
+	})
+}
@@ -0,0 +1,75 @@
+// Package http accepts writes for a router over HTTP.
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// Writer is implemented by router.Router. It must be safe for concurrent
+// use.
+type Writer interface {
+	WriteContext(ctx context.Context, data []byte) (err error)
+}
+
+// Handler routes the body of each POST with a Writer.
+type Handler struct {
+	writer  Writer
+	maxSize int64
+	logger  *slog.Logger
+}
+
+type HandlerOpts func(h *Handler)
+
+// WithMaxSize sets the largest body accepted. It defaults to 1MiB.
+func WithMaxSize(size int64) func(h *Handler) {
+	return func(h *Handler) {
+		h.maxSize = size
+	}
+}
+
+// WithLogger sets the logger failed writes are logged to. It defaults to
+// slog.Default().
+func WithLogger(logger *slog.Logger) func(h *Handler) {
+	return func(h *Handler) {
+		h.logger = logger
+	}
+}
+
+func NewHandler(writer Writer, opts ...HandlerOpts) *Handler {
+	h := &Handler{
+		writer:  writer,
+		maxSize: 1024 * 1024,
+		logger:  slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxSize))
+	if err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.writer.WriteContext(r.Context(), data); err != nil {
+		// The error can name range files, so it is only logged.
+		h.logger.Error("failed to write", "err", err)
+		http.Error(w, "failed to write", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,105 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	routerhttp "github.com/poy/petasos/router/http"
+)
+
+type TH struct {
+	*testing.T
+
+	writer   *stubWriter
+	logs     *bytes.Buffer
+	recorder *httptest.ResponseRecorder
+	handler  *routerhttp.Handler
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TH {
+		writer := &stubWriter{}
+		logs := &bytes.Buffer{}
+
+		return TH{
+			T:        t,
+			writer:   writer,
+			logs:     logs,
+			recorder: httptest.NewRecorder(),
+			handler: routerhttp.NewHandler(
+				writer,
+				routerhttp.WithMaxSize(10),
+				routerhttp.WithLogger(slog.New(slog.NewTextHandler(logs, nil))),
+			),
+		}
+	})
+
+	o.Spec("it writes the body", func(t TH) {
+		req := httptest.NewRequest(http.MethodPost, "/write", strings.NewReader("some-data"))
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(t, t.writer.data).To(Equal([][]byte{[]byte("some-data")}))
+	})
+
+	o.Spec("it returns a 503 when the write fails", func(t TH) {
+		t.writer.err = fmt.Errorf("some-error")
+
+		req := httptest.NewRequest(http.MethodPost, "/write", strings.NewReader("some-data"))
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	o.Spec("it logs the error instead of returning it", func(t TH) {
+		t.writer.err = fmt.Errorf("some-error")
+
+		req := httptest.NewRequest(http.MethodPost, "/write", strings.NewReader("some-data"))
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Body.String()).To(Not(ContainSubstring("some-error")))
+		Expect(t, t.logs.String()).To(ContainSubstring("err=some-error"))
+	})
+
+	o.Spec("it returns a 400 for a body that is too large", func(t TH) {
+		req := httptest.NewRequest(http.MethodPost, "/write", strings.NewReader("some-large-data"))
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(t, t.writer.data).To(HaveLen(0))
+	})
+
+	o.Spec("it returns a 405 for anything but POST", func(t TH) {
+		req := httptest.NewRequest(http.MethodGet, "/write", nil)
+		t.handler.ServeHTTP(t.recorder, req)
+
+		Expect(t, t.recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+}
+
+type stubWriter struct {
+	data [][]byte
+	err  error
+}
+
+func (s *stubWriter) WriteContext(ctx context.Context, data []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.data = append(s.data, data)
+	return nil
+}
@@ -1,6 +1,9 @@
 package router
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 type Counter struct {
 	mu      sync.Mutex
@@ -9,6 +12,8 @@ type Counter struct {
 
 type Metric struct {
 	WriteCount, ErrCount uint64
+	ByteCount            uint64
+	Latency              Histogram
 }
 
 func NewCounter() *Counter {
@@ -17,12 +22,13 @@ func NewCounter() *Counter {
 	}
 }
 
-func (c *Counter) IncSuccess(rn RangeName) {
+func (c *Counter) IncSuccess(rn RangeName, bytes uint64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	m := c.metrics[rn]
 	m.WriteCount++
+	m.ByteCount += bytes
 
 	c.metrics[rn] = m
 }
@@ -37,9 +43,31 @@ func (c *Counter) IncFailure(rn RangeName) {
 	c.metrics[rn] = m
 }
 
+func (c *Counter) RecordLatency(rn RangeName, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.metrics[rn]
+	m.Latency.Observe(latency)
+
+	c.metrics[rn] = m
+}
+
 func (c *Counter) Metrics(rn RangeName) (metric Metric) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	return c.metrics[rn]
 }
+
+// All returns the metrics for every range that has been recorded.
+func (c *Counter) All() (metrics map[RangeName]Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics = make(map[RangeName]Metric, len(c.metrics))
+	for rn, m := range c.metrics {
+		metrics[rn] = m
+	}
+	return metrics
+}
@@ -2,6 +2,7 @@ package router_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/poy/onpar"
 	. "github.com/poy/onpar/expect"
@@ -29,11 +30,12 @@ func TestMetrics(t *testing.T) {
 
 	o.Spec("it reports successes", func(t TM) {
 		rn := router.RangeName{Term: 1}
-		t.counter.IncSuccess(rn)
+		t.counter.IncSuccess(rn, 9)
 
 		metric := t.counter.Metrics(rn)
 		Expect(t, metric.WriteCount).To(Equal(uint64(1)))
 		Expect(t, metric.ErrCount).To(Equal(uint64(0)))
+		Expect(t, metric.ByteCount).To(Equal(uint64(9)))
 	})
 
 	o.Spec("it reports failures", func(t TM) {
@@ -44,4 +46,26 @@ func TestMetrics(t *testing.T) {
 		Expect(t, metric.WriteCount).To(Equal(uint64(0)))
 		Expect(t, metric.ErrCount).To(Equal(uint64(1)))
 	})
+
+	o.Spec("it reports every range", func(t TM) {
+		a := router.RangeName{Term: 1}
+		b := router.RangeName{Term: 2}
+		t.counter.IncSuccess(a, 1)
+		t.counter.IncFailure(b)
+
+		metrics := t.counter.All()
+		Expect(t, metrics).To(HaveLen(2))
+		Expect(t, metrics[a].WriteCount).To(Equal(uint64(1)))
+		Expect(t, metrics[b].ErrCount).To(Equal(uint64(1)))
+	})
+
+	o.Spec("it reports latencies", func(t TM) {
+		rn := router.RangeName{Term: 1}
+		t.counter.RecordLatency(rn, time.Millisecond)
+		t.counter.RecordLatency(rn, 3*time.Millisecond)
+
+		metric := t.counter.Metrics(rn)
+		Expect(t, metric.Latency.Count()).To(Equal(uint64(2)))
+		Expect(t, metric.Latency.Max).To(Equal(3 * time.Millisecond))
+	})
 }
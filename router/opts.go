@@ -0,0 +1,45 @@
+package router
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithCodec sets the codec used to decode range file names. It defaults to
+// JSONCodec.
+func WithCodec(codec RangeNameCodec) func(c *routerConfig) {
+	return func(c *routerConfig) {
+		c.codec = codec
+	}
+}
+
+// WithRefreshInterval sets how often the ranges are listed again to pick
+// up new ones. The ranges are also listed again after a failed write. It
+// defaults to 5 seconds.
+func WithRefreshInterval(interval time.Duration) func(c *routerConfig) {
+	return func(c *routerConfig) {
+		c.refresh = interval
+	}
+}
+
+// WithTopic scopes the router to the topic's range set (see TopicCodec).
+func WithTopic(topic Topic) func(c *routerConfig) {
+	return func(c *routerConfig) {
+		c.topic = topic
+	}
+}
+
+// WithTracer sets the Tracer used to span writes. It defaults to
+// NopTracer.
+func WithTracer(tracer Tracer) func(c *routerConfig) {
+	return func(c *routerConfig) {
+		c.tracer = tracer
+	}
+}
+
+// WithLogger sets the logger. It defaults to slog.Default().
+func WithLogger(logger *slog.Logger) func(c *routerConfig) {
+	return func(c *routerConfig) {
+		c.logger = logger
+	}
+}
@@ -1,7 +1,133 @@
 package router
 
-type RangeName struct {
-	Low, High uint64
-	Term      uint64
-	Rand      int64
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/poy/petasos/topology"
+)
+
+// RangeName is the span of hashes a range file covers and its term. It is
+// defined by topology so the router can use topology.Snapshot.
+type RangeName = topology.RangeName
+
+// RangeNameCodec converts RangeNames to and from file names. The codecs
+// here also decode names they would not encode (e.g., JSON with its fields
+// in another order) so existing files keep their ranges. Migrating from a
+// codec to itself (see package migrate) renames them to the encoded name.
+type RangeNameCodec = topology.RangeNameCodec
+
+// JSONCodec names files with the JSON encoding of the RangeName (e.g.,
+// {"Low":0,"High":10,"Term":1,"Rand":5}).
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(rn RangeName) (file string) {
+	data, _ := json.Marshal(rn)
+	return string(data)
+}
+
+func (JSONCodec) Decode(file string) (rn RangeName, err error) {
+	err = json.Unmarshal([]byte(file), &rn)
+	return rn, err
+}
+
+// HexCodec names files with the fixed-width hex encoding of the Low, High,
+// Term and Rand fields (64 characters).
+type HexCodec struct{}
+
+func (HexCodec) Encode(rn RangeName) (file string) {
+	return fmt.Sprintf("%016x%016x%016x%016x", rn.Low, rn.High, rn.Term, uint64(rn.Rand))
+}
+
+func (HexCodec) Decode(file string) (rn RangeName, err error) {
+	if len(file) != 64 {
+		return RangeName{}, fmt.Errorf("invalid hex range name: %q", file)
+	}
+
+	var fields [4]uint64
+	for i := range fields {
+		fields[i], err = strconv.ParseUint(file[i*16:(i+1)*16], 16, 64)
+		if err != nil {
+			return RangeName{}, fmt.Errorf("invalid hex range name: %q", file)
+		}
+	}
+
+	return RangeName{
+		Low:  fields[0],
+		High: fields[1],
+		Term: fields[2],
+		Rand: int64(fields[3]),
+	}, nil
+}
+
+// PathCodec names files with only characters that are safe in paths and
+// object keys (e.g., petasos_0_10_1_5 for Low, High, Term and Rand).
+type PathCodec struct{}
+
+const pathPrefix = "petasos"
+
+func (PathCodec) Encode(rn RangeName) (file string) {
+	return fmt.Sprintf("%s_%d_%d_%d_%d", pathPrefix, rn.Low, rn.High, rn.Term, rn.Rand)
+}
+
+func (PathCodec) Decode(file string) (rn RangeName, err error) {
+	parts := strings.Split(file, "_")
+	if len(parts) != 5 || parts[0] != pathPrefix {
+		return RangeName{}, fmt.Errorf("invalid path range name: %q", file)
+	}
+
+	var fields [3]uint64
+	for i := range fields {
+		fields[i], err = strconv.ParseUint(parts[i+1], 10, 64)
+		if err != nil {
+			return RangeName{}, fmt.Errorf("invalid path range name: %q", file)
+		}
+	}
+
+	r, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil {
+		return RangeName{}, fmt.Errorf("invalid path range name: %q", file)
+	}
+
+	return RangeName{
+		Low:  fields[0],
+		High: fields[1],
+		Term: fields[2],
+		Rand: r,
+	}, nil
+}
+
+// ParseCodec returns the codec with the given name: json, hex or path.
+func ParseCodec(name string) (codec RangeNameCodec, err error) {
+	switch name {
+	case "json":
+		return JSONCodec{}, nil
+	case "hex":
+		return HexCodec{}, nil
+	case "path":
+		return PathCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown codec: %q", name)
+	}
+}
+
+// AnyCodec decodes names written with the JSON, Hex or Path codec and
+// encodes with JSONCodec. It is for components that serve stores of any
+// codec (e.g., storage nodes).
+type AnyCodec struct{}
+
+func (AnyCodec) Encode(rn RangeName) (file string) {
+	return JSONCodec{}.Encode(rn)
+}
+
+func (AnyCodec) Decode(file string) (rn RangeName, err error) {
+	for _, codec := range []RangeNameCodec{JSONCodec{}, HexCodec{}, PathCodec{}} {
+		if rn, err = codec.Decode(file); err == nil {
+			return rn, nil
+		}
+	}
+
+	return RangeName{}, fmt.Errorf("not a range name: %q", file)
 }
@@ -0,0 +1,164 @@
+package router_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/router"
+)
+
+func TestJSONCodec(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it round trips a RangeName", func(t *testing.T) {
+		rn := router.RangeName{Low: 1, High: 18446744073709551615, Term: 3, Rand: -4}
+		codec := router.JSONCodec{}
+
+		decoded, err := codec.Decode(codec.Encode(rn))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, decoded).To(Equal(rn))
+	})
+
+	o.Spec("it encodes as JSON", func(t *testing.T) {
+		file := router.JSONCodec{}.Encode(router.RangeName{Low: 1, High: 2, Term: 3})
+		Expect(t, file).To(MatchJSON(`{"Low":1,"High":2,"Term":3,"Rand":0}`))
+	})
+
+	o.Spec("it returns an error for a non-petasos file", func(t *testing.T) {
+		_, err := router.JSONCodec{}.Decode("some-file")
+		Expect(t, err == nil).To(BeFalse())
+	})
+
+	o.Spec("it decodes a name it would not encode", func(t *testing.T) {
+		for _, file := range []string{
+			`{"High":10,"Low":0,"Term":1,"Rand":5}`,
+			`{"Low":0, "High":10, "Term":1, "Rand":5}`,
+			`{"Low":0,"High":10,"Term":1,"Rand":5,"Extra":1}`,
+		} {
+			rn, err := router.JSONCodec{}.Decode(file)
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, rn).To(Equal(router.RangeName{Low: 0, High: 10, Term: 1, Rand: 5}))
+		}
+	})
+}
+
+func TestHexCodec(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it round trips a RangeName", func(t *testing.T) {
+		rn := router.RangeName{Low: 1, High: 18446744073709551615, Term: 3, Rand: -4}
+		codec := router.HexCodec{}
+
+		decoded, err := codec.Decode(codec.Encode(rn))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, decoded).To(Equal(rn))
+	})
+
+	o.Spec("it encodes as fixed-width hex", func(t *testing.T) {
+		file := router.HexCodec{}.Encode(router.RangeName{Low: 1, High: 255, Term: 3})
+		Expect(t, file).To(Equal(
+			"0000000000000001" + "00000000000000ff" + "0000000000000003" + "0000000000000000",
+		))
+	})
+
+	o.Spec("it returns an error for a non-petasos file", func(t *testing.T) {
+		_, err := router.HexCodec{}.Decode("some-file")
+		Expect(t, err == nil).To(BeFalse())
+
+		_, err = router.HexCodec{}.Decode(strings.Repeat("z", 64))
+		Expect(t, err == nil).To(BeFalse())
+	})
+
+	o.Spec("it decodes a name it would not encode", func(t *testing.T) {
+		rn, err := router.HexCodec{}.Decode(
+			"0000000000000001" + "00000000000000FF" + "0000000000000003" + "0000000000000000",
+		)
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, rn).To(Equal(router.RangeName{Low: 1, High: 255, Term: 3}))
+	})
+}
+
+func TestPathCodec(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it round trips a RangeName", func(t *testing.T) {
+		rn := router.RangeName{Low: 1, High: 18446744073709551615, Term: 3, Rand: -4}
+		codec := router.PathCodec{}
+
+		decoded, err := codec.Decode(codec.Encode(rn))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, decoded).To(Equal(rn))
+	})
+
+	o.Spec("it encodes with path-safe characters", func(t *testing.T) {
+		file := router.PathCodec{}.Encode(router.RangeName{Low: 1, High: 2, Term: 3, Rand: 4})
+		Expect(t, file).To(Equal("petasos_1_2_3_4"))
+	})
+
+	o.Spec("it returns an error for a non-petasos file", func(t *testing.T) {
+		for _, file := range []string{"some-file", "other_1_2_3_4", "petasos_1_2_x_4"} {
+			_, err := router.PathCodec{}.Decode(file)
+			Expect(t, err == nil).To(BeFalse())
+		}
+	})
+
+	o.Spec("it decodes a name it would not encode", func(t *testing.T) {
+		for _, file := range []string{"petasos_01_2_3_4", "petasos_1_2_3_+4", "petasos_1_2_3_04"} {
+			rn, err := router.PathCodec{}.Decode(file)
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, rn).To(Equal(router.RangeName{Low: 1, High: 2, Term: 3, Rand: 4}))
+		}
+	})
+}
+
+func TestParseCodec(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it returns the named codec", func(t *testing.T) {
+		for name, expected := range map[string]router.RangeNameCodec{
+			"json": router.JSONCodec{},
+			"hex":  router.HexCodec{},
+			"path": router.PathCodec{},
+		} {
+			codec, err := router.ParseCodec(name)
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, codec).To(Equal(expected))
+		}
+	})
+
+	o.Spec("it returns an error for an unknown codec", func(t *testing.T) {
+		_, err := router.ParseCodec("some-codec")
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
+
+func TestAnyCodec(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it decodes names from every codec", func(t *testing.T) {
+		rn := router.RangeName{Low: 1, High: 18446744073709551615, Term: 3, Rand: -4}
+		for _, codec := range []router.RangeNameCodec{router.JSONCodec{}, router.HexCodec{}, router.PathCodec{}} {
+			decoded, err := router.AnyCodec{}.Decode(codec.Encode(rn))
+			Expect(t, err == nil).To(BeTrue())
+			Expect(t, decoded).To(Equal(rn))
+		}
+	})
+
+	o.Spec("it returns an error for a non-petasos file", func(t *testing.T) {
+		_, err := router.AnyCodec{}.Decode("some-file")
+		Expect(t, err == nil).To(BeFalse())
+	})
+}
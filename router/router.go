@@ -1,9 +1,14 @@
 package router
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/poy/petasos/topology"
 )
 
 type Writer interface {
@@ -21,155 +26,331 @@ type Hasher interface {
 }
 
 type MetricsCounter interface {
-	IncSuccess(name RangeName)
+	IncSuccess(name RangeName, bytes uint64)
 	IncFailure(name RangeName)
+	RecordLatency(name RangeName, latency time.Duration)
 }
 
 type writerInfo struct {
-	writer    Writer
+	writer    *rangeWriter
+	file      string
 	rangeName RangeName
 }
 
+// Router routes each write to the range that owns its hash. It is safe for
+// concurrent use: writes to the same range are serialized, writes to
+// different ranges are not.
 type Router struct {
 	fs             FileSystem
 	hasher         Hasher
 	metricsCounter MetricsCounter
-
-	ranges  []hashRange
-	writers map[uint64]writerInfo
+	conf           routerConfig
+
+	// mu guards the snapshot and the writers. It is not held while
+	// writing.
+	mu       sync.Mutex
+	snapshot *topology.Snapshot
+	listedAt time.Time
+	writers  map[string]writerInfo
 }
 
-type hashRange struct {
-	file string
-	r    RangeName
+type routerConfig struct {
+	codec   RangeNameCodec
+	topic   Topic
+	refresh time.Duration
+	tracer  Tracer
+	logger  *slog.Logger
 }
 
-func New(fs FileSystem, hasher Hasher, metricsCounter MetricsCounter) *Router {
+type RouterOpts func(c *routerConfig)
+
+func New(fs FileSystem, hasher Hasher, metricsCounter MetricsCounter, opts ...RouterOpts) *Router {
+	conf := routerConfig{
+		codec:   JSONCodec{},
+		refresh: 5 * time.Second,
+		tracer:  NopTracer{},
+		logger:  slog.Default(),
+	}
+
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	conf.codec = conf.topic.Codec(conf.codec)
+
 	return &Router{
 		fs:             fs,
 		hasher:         hasher,
 		metricsCounter: metricsCounter,
+		conf:           conf,
 	}
 }
 
 func (r *Router) Write(data []byte) (err error) {
-	hash, err := r.hasher.Hash(data)
+	return r.WriteContext(context.Background(), data)
+}
+
+// WriteContext is Write with spans started from ctx.
+func (r *Router) WriteContext(ctx context.Context, data []byte) (err error) {
+	ctx, span := r.conf.tracer.Start(ctx, "petasos.router.Write")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	hash, err := r.hash(ctx, data)
 	if err != nil {
 		return err
 	}
+	span.SetHash(hash)
 
-	writer, err := r.fetchWriter(hash)
+	writer, err := r.lookup(ctx, hash)
 	if err != nil {
-		r.writeFailure()
+		r.writeFailure(nil)
 		return err
 	}
+	span.SetRange(writer.rangeName)
 
-	if err = writer.writer.Write(data); err != nil {
-		r.writeFailure()
-		r.metricsCounter.IncFailure(writer.rangeName)
+	err = r.write(ctx, writer, data)
+	if err == nil {
+		return nil
+	}
+
+	// A closed writer was already dealt with by whoever closed it.
+	if !errors.Is(err, errWriterClosed) {
+		r.writeFailure(writer.writer)
+	}
+
+	// The range may have been retired while the write waited (its writer
+	// closed or its file superseded), in which case nothing was written.
+	// The write is retried once against the hash's new owner.
+	retry, lookupErr := r.lookup(ctx, hash)
+	if lookupErr != nil {
+		return err
+	}
 
+	if retry.file == writer.file && !errors.Is(err, errWriterClosed) {
 		return err
 	}
+	span.SetRange(retry.rangeName)
 
-	r.metricsCounter.IncSuccess(writer.rangeName)
+	if err := r.write(ctx, retry, data); err != nil {
+		r.writeFailure(retry.writer)
+		return err
+	}
 
 	return nil
 }
 
-func (r *Router) writeFailure() {
-	for _, w := range r.writers {
-		w.writer.Close()
+func (r *Router) write(ctx context.Context, writer writerInfo, data []byte) error {
+	_, span := r.conf.tracer.Start(ctx, "petasos.router.FileSystemWrite")
+	defer span.End()
+	span.SetRange(writer.rangeName)
+
+	start := time.Now()
+	err := writer.writer.Write(data)
+	r.metricsCounter.RecordLatency(writer.rangeName, time.Since(start))
+
+	if err != nil {
+		span.RecordError(err)
+		r.metricsCounter.IncFailure(writer.rangeName)
+		return err
 	}
 
-	r.ranges = nil
-	r.writers = nil
+	r.metricsCounter.IncSuccess(writer.rangeName, uint64(len(data)))
+
+	return nil
 }
 
-func (r *Router) fetchWriter(hash uint64) (writer writerInfo, err error) {
-	writer, ok := r.writers[hash]
-	if ok {
-		return writer, nil
+func (r *Router) hash(ctx context.Context, data []byte) (uint64, error) {
+	_, span := r.conf.tracer.Start(ctx, "petasos.router.Hash")
+	defer span.End()
+
+	hash, err := r.hasher.Hash(data)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
 	}
+	span.SetHash(hash)
+
+	return hash, nil
+}
 
-	file, err := r.fetchFromRange(hash)
+func (r *Router) lookup(ctx context.Context, hash uint64) (writerInfo, error) {
+	_, span := r.conf.tracer.Start(ctx, "petasos.router.LookupRange")
+	defer span.End()
+	span.SetHash(hash)
+
+	writer, err := r.fetchWriter(hash)
 	if err != nil {
+		span.RecordError(err)
 		return writerInfo{}, err
 	}
+	span.SetRange(writer.rangeName)
+
+	return writer, nil
+}
+
+// writeFailure closes the writer that failed, if any, and lists the ranges
+// again on the next write. The other writers are left open.
+func (r *Router) writeFailure(failed *rangeWriter) {
+	r.mu.Lock()
+	r.snapshot = nil
+	if failed == nil {
+		r.mu.Unlock()
+		return
+	}
+
+	for file, w := range r.writers {
+		if w.writer == failed {
+			delete(r.writers, file)
+		}
+	}
+	r.mu.Unlock()
 
-	w, err := r.fs.Writer(file)
+	// Close waits for the write in progress, so r.mu is not held.
+	failed.Close()
+}
+
+// fetchWriter returns the writer for the range that owns the hash. Each
+// range's file is opened once and shared by every hash it owns.
+func (r *Router) fetchWriter(hash uint64) (writer writerInfo, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner, err := r.fetchFromRange(hash)
 	if err != nil {
 		return writerInfo{}, err
 	}
 
-	var rangeName RangeName
-	if err := json.Unmarshal([]byte(file), &rangeName); err != nil {
+	writer, ok := r.writers[owner.File]
+	if ok {
+		return writer, nil
+	}
+
+	w, err := r.fs.Writer(owner.File)
+	if err != nil {
 		return writerInfo{}, err
 	}
 
 	writer = writerInfo{
-		writer:    w,
-		rangeName: rangeName,
+		writer:    &rangeWriter{writer: w},
+		file:      owner.File,
+		rangeName: owner.Name,
 	}
-	r.writers[hash] = writer
+	r.writers[owner.File] = writer
 
 	return writer, nil
 }
 
-func (r *Router) fetchFromRange(hash uint64) (file string, err error) {
-	if r.ranges == nil {
-		r.ranges, err = r.setupRanges()
-		if err != nil {
-			return "", err
+func (r *Router) fetchFromRange(hash uint64) (owner topology.Range, err error) {
+	if r.snapshot == nil || time.Since(r.listedAt) >= r.conf.refresh {
+		if err := r.refresh(); err != nil {
+			return topology.Range{}, err
+		}
+	}
+
+	owner, ok := r.snapshot.Owner(hash)
+	if !ok {
+		return topology.Range{}, fmt.Errorf("%d does not have a home", hash)
+	}
+
+	return owner, nil
+}
+
+// refresh lists the ranges again and closes the writers of the ranges that
+// no longer own any hash. If listing fails, the previous snapshot is kept.
+func (r *Router) refresh() error {
+	snapshot, err := r.setupSnapshot()
+	if err != nil {
+		if r.snapshot == nil {
+			return err
 		}
-		r.writers = make(map[uint64]writerInfo)
+
+		r.conf.logger.Warn("failed to refresh ranges", "err", err)
+		r.listedAt = time.Now()
+		return nil
+	}
+
+	owners := make(map[string]bool)
+	for _, i := range snapshot.Intervals() {
+		owners[i.Owner.File] = true
 	}
 
-	var matchedRange hashRange
-	for _, hashRange := range r.ranges {
-		if hash >= hashRange.r.Low && hash <= hashRange.r.High && matchedRange.r.Term <= hashRange.r.Term {
-			matchedRange = hashRange
+	for file, w := range r.writers {
+		if !owners[file] {
+			w.writer.Close()
+			delete(r.writers, file)
 		}
 	}
 
-	if matchedRange.file == "" {
-		return "", fmt.Errorf("%d does not have a home", hash)
+	if r.writers == nil {
+		r.writers = make(map[string]writerInfo)
 	}
+	r.snapshot, r.listedAt = snapshot, time.Now()
 
-	return matchedRange.file, nil
+	return nil
 }
 
-func (r *Router) setupRanges() (ranges []hashRange, err error) {
+func (r *Router) setupSnapshot() (*topology.Snapshot, error) {
 	list, err := r.fs.List()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, file := range list {
-		var rn RangeName
-		err := json.Unmarshal([]byte(file), &rn)
-		if err != nil {
-			log.Printf("Non-petasos range: %s", file)
-			continue
-		}
-
-		ranges = append(ranges, hashRange{
-			file: file,
-			r:    rn,
-		})
+	snapshot := topology.New(list, r.conf.codec)
+	for _, file := range snapshot.Invalid() {
+		r.conf.logger.Warn("non-petasos range", "file", file)
 	}
 
-	if len(ranges) == 0 {
+	if len(snapshot.Ranges()) == 0 {
 		return nil, fmt.Errorf("empty ranges")
 	}
 
-	return ranges, nil
+	return snapshot, nil
 }
 
 func (r *Router) lowHigh(file string) (low, high uint64, err error) {
-	var rn RangeName
-	if err := json.Unmarshal([]byte(file), &rn); err != nil {
+	rn, err := r.conf.codec.Decode(file)
+	if err != nil {
 		return 0, 0, err
 	}
 
 	return rn.Low, rn.High, nil
 }
+
+var errWriterClosed = errors.New("writer closed")
+
+// rangeWriter serializes the writes to a range's file. Close waits for the
+// write in progress, and the writes after it fail with errWriterClosed so
+// they are retried against the range's new owner.
+type rangeWriter struct {
+	mu     sync.Mutex
+	writer Writer
+	closed bool
+}
+
+func (w *rangeWriter) Write(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errWriterClosed
+	}
+
+	return w.writer.Write(data)
+}
+
+func (w *rangeWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	w.closed = true
+	w.writer.Close()
+}
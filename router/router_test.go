@@ -3,10 +3,17 @@
 package router_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/poy/eachers/testhelpers"
 	"github.com/poy/onpar"
 	. "github.com/poy/onpar/expect"
 	. "github.com/poy/onpar/matchers"
@@ -107,6 +114,50 @@ func TestRouter(t *testing.T) {
 			))
 		})
 
+		o.Spec("it reports how many bytes were written", func(t TR) {
+			t.mockHasher.HashOutput.Hash <- 1000000
+			t.r.Write([]byte("some-data"))
+
+			Expect(t, t.mockMetricsCounter.IncSuccessInput.Bytes).To(ViaPolling(
+				Chain(Receive(), Equal(uint64(9))),
+			))
+		})
+
+		o.Spec("it records the write latency for the range", func(t TR) {
+			t.mockHasher.HashOutput.Hash <- 1000000
+			t.r.Write([]byte("some-data"))
+
+			Expect(t, t.mockMetricsCounter.RecordLatencyInput.Name).To(ViaPolling(
+				Chain(Receive(), Equal(router.RangeName{
+					Low:  0,
+					High: 9223372036854775807,
+					Term: 0,
+				})),
+			))
+		})
+
+		o.Spec("it traces the write", func(t TR) {
+			tracer := &spyTracer{}
+			r := router.New(t.mockFileSystem, t.mockHasher, t.mockMetricsCounter, router.WithTracer(tracer))
+
+			t.mockHasher.HashOutput.Hash <- 1000000
+			err := r.WriteContext(context.Background(), []byte("some-data"))
+			Expect(t, err == nil).To(BeTrue())
+
+			Expect(t, tracer.names).To(Equal([]string{
+				"petasos.router.Write",
+				"petasos.router.Hash",
+				"petasos.router.LookupRange",
+				"petasos.router.FileSystemWrite",
+			}))
+			Expect(t, tracer.spans[3].rn).To(Equal(router.RangeName{
+				Low:  0,
+				High: 9223372036854775807,
+				Term: 0,
+			}))
+			Expect(t, tracer.spans[0].ended).To(BeTrue())
+		})
+
 		o.Group("when a range becomes invalid", func() {
 			o.BeforeEach(func(t TR) TR {
 				t.mockHasher.HashOutput.Hash <- 1000000
@@ -178,6 +229,71 @@ func TestRouter(t *testing.T) {
 		})
 	})
 
+	o.Group("when a file is not a petasos range", func() {
+		o.BeforeEach(func(t TR) TR {
+			t.mockFileSystem.ListOutput.File <- []string{
+				"some-file",
+				buildRangeName(0, 18446744073709551615, 0),
+			}
+			t.mockFileSystem.ListOutput.Err <- nil
+
+			t.mockHasher.HashOutput.Hash <- 1000000
+			t.mockHasher.HashOutput.Err <- nil
+
+			t.mockFileSystem.WriterOutput.Writer <- t.mockWriter
+			t.mockFileSystem.WriterOutput.Err <- nil
+
+			t.mockWriter.WriteOutput.Err <- nil
+
+			return t
+		})
+
+		o.Spec("it logs the file to the given logger", func(t TR) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, nil))
+			r := router.New(t.mockFileSystem, t.mockHasher, t.mockMetricsCounter, router.WithLogger(logger))
+
+			err := r.Write([]byte("some-data"))
+			Expect(t, err == nil).To(BeTrue())
+
+			Expect(t, buf.String()).To(ContainSubstring("non-petasos range"))
+			Expect(t, buf.String()).To(ContainSubstring("file=some-file"))
+		})
+	})
+
+	o.Group("when the ranges use another codec", func() {
+		o.BeforeEach(func(t TR) TR {
+			t.mockFileSystem.ListOutput.File <- []string{
+				router.HexCodec{}.Encode(router.RangeName{Low: 0, High: 18446744073709551615, Term: 0}),
+			}
+			t.mockFileSystem.ListOutput.Err <- nil
+
+			t.mockHasher.HashOutput.Hash <- 1000000
+			t.mockHasher.HashOutput.Err <- nil
+
+			t.mockFileSystem.WriterOutput.Writer <- t.mockWriter
+			t.mockFileSystem.WriterOutput.Err <- nil
+
+			t.mockWriter.WriteOutput.Err <- nil
+
+			return t
+		})
+
+		o.Spec("it decodes the ranges with the given codec", func(t TR) {
+			r := router.New(t.mockFileSystem, t.mockHasher, t.mockMetricsCounter, router.WithCodec(router.HexCodec{}))
+
+			err := r.Write([]byte("some-data"))
+			Expect(t, err == nil).To(BeTrue())
+
+			Expect(t, t.mockMetricsCounter.IncSuccessInput.Name).To(ViaPolling(
+				Chain(Receive(), Equal(router.RangeName{
+					Low:  0,
+					High: 18446744073709551615,
+				})),
+			))
+		})
+	})
+
 	o.Group("hasher returns an error", func() {
 		o.BeforeEach(func(t TR) TR {
 			close(t.mockFileSystem.ListOutput.File)
@@ -195,6 +311,296 @@ func TestRouter(t *testing.T) {
 	})
 }
 
+func TestRouterWriters(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TR {
+		mockFileSystem := newMockFileSystem()
+		mockHasher := newMockHasher()
+		mockWriter := newMockWriter()
+		mockMetricsCounter := newMockMetricsCounter()
+
+		close(mockHasher.HashOutput.Err)
+		close(mockWriter.WriteOutput.Err)
+		testhelpers.AlwaysReturn(mockFileSystem.WriterOutput.Writer, mockWriter)
+		close(mockFileSystem.WriterOutput.Err)
+
+		return TR{
+			T:                  t,
+			mockFileSystem:     mockFileSystem,
+			mockHasher:         mockHasher,
+			mockWriter:         mockWriter,
+			mockMetricsCounter: mockMetricsCounter,
+		}
+	})
+
+	o.Spec("it opens each range's file once", func(t TR) {
+		testhelpers.AlwaysReturn(t.mockFileSystem.ListOutput.File, []string{
+			buildRangeName(0, 18446744073709551615, 0),
+		})
+		close(t.mockFileSystem.ListOutput.Err)
+
+		r := router.New(t.mockFileSystem, t.mockHasher, t.mockMetricsCounter)
+
+		for _, hash := range []uint64{1, 2, 3} {
+			t.mockHasher.HashOutput.Hash <- hash
+			Expect(t, r.Write([]byte("some-data")) == nil).To(BeTrue())
+		}
+
+		Expect(t, t.mockFileSystem.WriterCalled).To(HaveLen(1))
+	})
+
+	o.Spec("it writes to new ranges once it lists them again", func(t TR) {
+		t.mockFileSystem.ListOutput.File <- []string{
+			buildRangeName(0, 18446744073709551615, 0),
+		}
+		testhelpers.AlwaysReturn(t.mockFileSystem.ListOutput.File, []string{
+			buildRangeName(0, 18446744073709551615, 0),
+			buildRangeName(0, 9223372036854775807, 1),
+			buildRangeName(9223372036854775808, 18446744073709551615, 2),
+		})
+		close(t.mockFileSystem.ListOutput.Err)
+
+		r := router.New(t.mockFileSystem, t.mockHasher, t.mockMetricsCounter, router.WithRefreshInterval(0))
+
+		for i := 0; i < 2; i++ {
+			t.mockHasher.HashOutput.Hash <- 1
+			Expect(t, r.Write([]byte("some-data")) == nil).To(BeTrue())
+		}
+
+		Expect(t, t.mockFileSystem.WriterInput.Name).To(Chain(Receive(), Equal(buildRangeName(0, 18446744073709551615, 0))))
+		Expect(t, t.mockFileSystem.WriterInput.Name).To(Chain(Receive(), Equal(buildRangeName(0, 9223372036854775807, 1))))
+		Expect(t, t.mockWriter.CloseCalled).To(HaveLen(1))
+	})
+}
+
+type TC struct {
+	*testing.T
+
+	fs *stubFileSystem
+	r  *router.Router
+}
+
+func TestRouterConcurrency(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.BeforeEach(func(t *testing.T) TC {
+		fs := &stubFileSystem{
+			files: []string{
+				buildRangeName(0, 9223372036854775807, 0),
+				buildRangeName(9223372036854775808, 18446744073709551615, 1),
+			},
+			writers: map[string]*stubWriter{
+				buildRangeName(0, 9223372036854775807, 0):                    newStubWriter(),
+				buildRangeName(9223372036854775808, 18446744073709551615, 1): newStubWriter(),
+			},
+		}
+
+		return TC{
+			T:  t,
+			fs: fs,
+			r:  router.New(fs, dataHasher{}, router.NewCounter()),
+		}
+	})
+
+	o.Spec("it writes to other ranges while a write is in progress", func(t TC) {
+		low := t.fs.writers[buildRangeName(0, 9223372036854775807, 0)]
+		low.block = make(chan struct{})
+		defer close(low.block)
+
+		go t.r.Write([]byte("1"))
+		Expect(t, low.started).To(ViaPolling(Receive()))
+
+		done := make(chan error, 1)
+		go func() {
+			done <- t.r.Write([]byte("18446744073709551615"))
+		}()
+
+		Expect(t, done).To(ViaPolling(
+			Chain(Receive(), Equal(nil)),
+		))
+	})
+
+	o.Spec("it serializes the writes to a range", func(t TC) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				t.r.Write([]byte("1"))
+			}()
+		}
+		wg.Wait()
+
+		low := t.fs.writers[buildRangeName(0, 9223372036854775807, 0)]
+		Expect(t, low.writes()).To(Equal(20))
+		Expect(t, low.overlapped()).To(BeFalse())
+	})
+
+	o.Spec("it retries a write once against the range that supersedes its own", func(t TC) {
+		Expect(t, t.r.Write([]byte("1"))).To(BeNil())
+		Expect(t, t.r.Write([]byte("18446744073709551615"))).To(BeNil())
+
+		low := t.fs.writers[buildRangeName(0, 9223372036854775807, 0)]
+		high := t.fs.writers[buildRangeName(9223372036854775808, 18446744073709551615, 1)]
+		newLow := newStubWriter()
+
+		t.fs.mu.Lock()
+		t.fs.files = append(t.fs.files, buildRangeName(0, 9223372036854775807, 2))
+		t.fs.writers[buildRangeName(0, 9223372036854775807, 2)] = newLow
+		t.fs.mu.Unlock()
+		low.fail(fmt.Errorf("superseded"))
+
+		Expect(t, t.r.Write([]byte("1"))).To(BeNil())
+		Expect(t, newLow.writes()).To(Equal(1))
+		Expect(t, low.isClosed()).To(BeTrue())
+		Expect(t, high.isClosed()).To(BeFalse())
+	})
+}
+
+// dataHasher hashes data that is a number to that number.
+type dataHasher struct{}
+
+func (dataHasher) Hash(data []byte) (uint64, error) {
+	return strconv.ParseUint(string(data), 10, 64)
+}
+
+type stubFileSystem struct {
+	mu      sync.Mutex
+	files   []string
+	writers map[string]*stubWriter
+}
+
+func (f *stubFileSystem) List() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.files...), nil
+}
+
+func (f *stubFileSystem) Writer(name string) (router.Writer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w, ok := f.writers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown file: %s", name)
+	}
+
+	return w, nil
+}
+
+// stubWriter records overlapping writes. When block is set, each write
+// signals started and waits for block to be closed. Once it fails, every
+// write returns the error.
+type stubWriter struct {
+	block   chan struct{}
+	started chan bool
+
+	mu      sync.Mutex
+	active  int
+	count   int
+	overlap bool
+	err     error
+	closed  bool
+}
+
+func newStubWriter() *stubWriter {
+	return &stubWriter{
+		started: make(chan bool, 100),
+	}
+}
+
+func (w *stubWriter) Write(data []byte) error {
+	w.mu.Lock()
+	if w.err != nil {
+		w.mu.Unlock()
+		return w.err
+	}
+	w.active++
+	w.count++
+	if w.active > 1 {
+		w.overlap = true
+	}
+	w.mu.Unlock()
+
+	if w.block != nil {
+		w.started <- true
+		<-w.block
+	} else {
+		time.Sleep(time.Millisecond)
+	}
+
+	w.mu.Lock()
+	w.active--
+	w.mu.Unlock()
+
+	return nil
+}
+
+func (w *stubWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+}
+
+func (w *stubWriter) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.err = err
+}
+
+func (w *stubWriter) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.closed
+}
+
+func (w *stubWriter) writes() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.count
+}
+
+func (w *stubWriter) overlapped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.overlap
+}
+
+type spyTracer struct {
+	names []string
+	spans []*spySpan
+}
+
+func (t *spyTracer) Start(ctx context.Context, name string) (context.Context, router.Span) {
+	s := &spySpan{}
+	t.names = append(t.names, name)
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+type spySpan struct {
+	rn    router.RangeName
+	hash  uint64
+	err   error
+	ended bool
+}
+
+func (s *spySpan) SetRange(rn router.RangeName) { s.rn = rn }
+func (s *spySpan) SetHash(hash uint64)          { s.hash = hash }
+func (s *spySpan) RecordError(err error)        { s.err = err }
+func (s *spySpan) End()                         { s.ended = true }
+
 func buildRangeName(low, high, term uint64) string {
 	rn := router.RangeName{
 		Low:  low,
@@ -0,0 +1,100 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/poy/petasos/topology"
+)
+
+// ErrOtherTopic is returned by a TopicCodec for files that are not in its
+// topic. Those files belong to another range set and are skipped.
+var ErrOtherTopic = topology.ErrOtherTopic
+
+// TopicCodec scopes a codec to a topic. Files are named topic/<name> so
+// that several independent range sets can share one store. When topics are
+// used, every router, reader and maintainer of the store should be given
+// one.
+type TopicCodec struct {
+	prefix string
+	codec  RangeNameCodec
+}
+
+// NewTopicCodec returns an error for an empty topic or one ending in a
+// slash.
+func NewTopicCodec(topic string, codec RangeNameCodec) (TopicCodec, error) {
+	t, err := ParseTopic(topic)
+	if err != nil {
+		return TopicCodec{}, err
+	}
+
+	return t.topicCodec(codec), nil
+}
+
+func (c TopicCodec) Encode(rn RangeName) (file string) {
+	return c.prefix + c.codec.Encode(rn)
+}
+
+func (c TopicCodec) Decode(file string) (rn RangeName, err error) {
+	name := strings.TrimPrefix(file, c.prefix)
+	if name == file || strings.Contains(name, "/") {
+		return RangeName{}, ErrOtherTopic
+	}
+
+	return c.codec.Decode(name)
+}
+
+// Topic is a topic name validated by ParseTopic. It is given to WithTopic
+// (and the reader and maintainer equivalents) so that invalid topics are
+// reported before anything is constructed. The zero Topic is no topic.
+type Topic struct {
+	name string
+}
+
+// ParseTopic returns an error for an empty topic or one ending in a slash.
+func ParseTopic(name string) (Topic, error) {
+	if name == "" || strings.HasSuffix(name, "/") {
+		return Topic{}, fmt.Errorf("invalid topic: %q", name)
+	}
+
+	return Topic{name: name}, nil
+}
+
+func (t Topic) String() string {
+	return t.name
+}
+
+// Codec scopes the codec to the topic with a TopicCodec. The zero Topic
+// scopes it to the files outside of every topic: it returns ErrOtherTopic
+// for any name with a slash.
+func (t Topic) Codec(codec RangeNameCodec) RangeNameCodec {
+	if t.name == "" {
+		return noTopicCodec{codec: codec}
+	}
+
+	return t.topicCodec(codec)
+}
+
+func (t Topic) topicCodec(codec RangeNameCodec) TopicCodec {
+	return TopicCodec{
+		prefix: t.name + "/",
+		codec:  codec,
+	}
+}
+
+// noTopicCodec is the codec of the range set without a topic.
+type noTopicCodec struct {
+	codec RangeNameCodec
+}
+
+func (c noTopicCodec) Encode(rn RangeName) (file string) {
+	return c.codec.Encode(rn)
+}
+
+func (c noTopicCodec) Decode(file string) (rn RangeName, err error) {
+	if strings.Contains(file, "/") {
+		return RangeName{}, ErrOtherTopic
+	}
+
+	return c.codec.Decode(file)
+}
@@ -0,0 +1,102 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/router"
+)
+
+func TestTopicCodec(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it prefixes the file with the topic", func(t *testing.T) {
+		codec, err := router.NewTopicCodec("some-topic", router.PathCodec{})
+		Expect(t, err == nil).To(BeTrue())
+		file := codec.Encode(router.RangeName{Low: 1, High: 2, Term: 3, Rand: 4})
+		Expect(t, file).To(Equal("some-topic/petasos_1_2_3_4"))
+	})
+
+	o.Spec("it round trips a RangeName", func(t *testing.T) {
+		rn := router.RangeName{Low: 1, High: 18446744073709551615, Term: 3, Rand: -4}
+		codec, err := router.NewTopicCodec("some/topic", router.JSONCodec{})
+		Expect(t, err == nil).To(BeTrue())
+
+		decoded, err := codec.Decode(codec.Encode(rn))
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, decoded).To(Equal(rn))
+	})
+
+	o.Spec("it returns ErrOtherTopic for files in other topics", func(t *testing.T) {
+		codec, err := router.NewTopicCodec("some-topic", router.PathCodec{})
+		Expect(t, err == nil).To(BeTrue())
+
+		for _, file := range []string{
+			"petasos_1_2_3_4",
+			"other-topic/petasos_1_2_3_4",
+			"some-topic/nested/petasos_1_2_3_4",
+		} {
+			_, err := codec.Decode(file)
+			Expect(t, err).To(Equal(router.ErrOtherTopic))
+		}
+	})
+
+	o.Spec("it returns the codec's error for other files in the topic", func(t *testing.T) {
+		codec, err := router.NewTopicCodec("some-topic", router.PathCodec{})
+		Expect(t, err == nil).To(BeTrue())
+
+		_, err = codec.Decode("some-topic/some-file")
+		Expect(t, err == nil).To(BeFalse())
+		Expect(t, err).To(Not(Equal(router.ErrOtherTopic)))
+	})
+
+	o.Spec("it returns an error for an invalid topic", func(t *testing.T) {
+		for _, topic := range []string{"", "some-topic/"} {
+			_, err := router.NewTopicCodec(topic, router.JSONCodec{})
+			Expect(t, err == nil).To(BeFalse())
+		}
+	})
+}
+
+func TestTopic(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Spec("it returns an error for an invalid topic", func(t *testing.T) {
+		for _, topic := range []string{"", "some-topic/"} {
+			_, err := router.ParseTopic(topic)
+			Expect(t, err == nil).To(BeFalse())
+		}
+	})
+
+	o.Spec("it scopes the codec to the topic", func(t *testing.T) {
+		topic, err := router.ParseTopic("some-topic")
+		Expect(t, err == nil).To(BeTrue())
+
+		file := topic.Codec(router.PathCodec{}).Encode(router.RangeName{Low: 1, High: 2, Term: 3, Rand: 4})
+		Expect(t, file).To(Equal("some-topic/petasos_1_2_3_4"))
+	})
+
+	o.Spec("it leaves the names as they are without a topic", func(t *testing.T) {
+		var topic router.Topic
+		codec := topic.Codec(router.PathCodec{})
+
+		rn := router.RangeName{Low: 1, High: 2, Term: 3, Rand: 4}
+		Expect(t, codec.Encode(rn)).To(Equal("petasos_1_2_3_4"))
+
+		decoded, err := codec.Decode("petasos_1_2_3_4")
+		Expect(t, err == nil).To(BeTrue())
+		Expect(t, decoded).To(Equal(rn))
+	})
+
+	o.Spec("it returns ErrOtherTopic for files in a topic without a topic", func(t *testing.T) {
+		var topic router.Topic
+		_, err := topic.Codec(router.PathCodec{}).Decode("some-topic/petasos_1_2_3_4")
+		Expect(t, err).To(Equal(router.ErrOtherTopic))
+	})
+}
@@ -0,0 +1,31 @@
+package router
+
+import "context"
+
+// Tracer starts spans around the work petasos does. The oteltrace package
+// adapts an OpenTelemetry tracer.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a unit of traced work.
+type Span interface {
+	SetRange(rn RangeName)
+	SetHash(hash uint64)
+	RecordError(err error)
+	End()
+}
+
+// NopTracer is the default Tracer. Its spans do nothing.
+type NopTracer struct{}
+
+func (NopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetRange(rn RangeName) {}
+func (nopSpan) SetHash(hash uint64)   {}
+func (nopSpan) RecordError(err error) {}
+func (nopSpan) End()                  {}
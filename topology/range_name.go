@@ -0,0 +1,33 @@
+package topology
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// ErrOtherTopic is returned by a RangeNameCodec for files that are not in
+// its topic. Those files belong to another range set and are skipped.
+var ErrOtherTopic = errors.New("file is not in the topic")
+
+// RangeName is the span of hashes a range file covers and its term.
+type RangeName struct {
+	Low, High uint64
+	Term      uint64
+	Rand      int64
+}
+
+// LogValue logs the range's bounds and term as low, high and term.
+func (rn RangeName) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("low", rn.Low),
+		slog.Uint64("high", rn.High),
+		slog.Uint64("term", rn.Term),
+	)
+}
+
+// RangeNameCodec converts RangeNames to and from file names. Every
+// component reading or writing range files must use the same codec.
+type RangeNameCodec interface {
+	Encode(rn RangeName) (file string)
+	Decode(file string) (rn RangeName, err error)
+}
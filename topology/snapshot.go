@@ -0,0 +1,294 @@
+// Package topology describes which range file owns each hash at a point in
+// time.
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const maxHash = 18446744073709551615
+
+// Range is a decoded range file.
+type Range struct {
+	File string
+	Name RangeName
+}
+
+// Interval is a span of hashes owned by a single range.
+type Interval struct {
+	Low, High uint64
+	Owner     Range
+}
+
+// Gap is a span of hashes without a range.
+type Gap struct {
+	Low, High uint64
+}
+
+// Overlap is a span of hashes covered by two ranges that both still own
+// hashes. Newer owns the span.
+type Overlap struct {
+	Low, High    uint64
+	Older, Newer Range
+}
+
+// Snapshot is the topology described by a FileSystem.List() result. A hash
+// is owned by the range with the highest term that covers it.
+type Snapshot struct {
+	ranges    []Range
+	invalid   []string
+	intervals []Interval
+	gaps      []Gap
+	owning    map[int]bool
+}
+
+// New builds a Snapshot from the files. Files the codec can not decode are
+// reported by Invalid, except those in other topics which are ignored.
+func New(files []string, codec RangeNameCodec) *Snapshot {
+	s := &Snapshot{
+		owning: make(map[int]bool),
+	}
+
+	for _, file := range files {
+		rn, err := codec.Decode(file)
+		if err == ErrOtherTopic {
+			continue
+		}
+
+		if err != nil {
+			s.invalid = append(s.invalid, file)
+			continue
+		}
+
+		s.ranges = append(s.ranges, Range{File: file, Name: rn})
+	}
+
+	sort.SliceStable(s.ranges, func(i, j int) bool {
+		return s.ranges[i].Name.Term < s.ranges[j].Name.Term
+	})
+
+	s.sweep()
+
+	return s
+}
+
+// sweep splits the hash space at every range boundary and finds the owner
+// of each piece.
+func (s *Snapshot) sweep() {
+	points := []uint64{0}
+	for _, r := range s.ranges {
+		if r.Name.Low > r.Name.High {
+			continue
+		}
+
+		points = append(points, r.Name.Low)
+		if r.Name.High != maxHash {
+			points = append(points, r.Name.High+1)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+	for i, low := range points {
+		if i > 0 && points[i-1] == low {
+			continue
+		}
+
+		high := uint64(maxHash)
+		for _, next := range points[i+1:] {
+			if next != low {
+				high = next - 1
+				break
+			}
+		}
+
+		idx, ok := s.owner(low)
+		if !ok {
+			s.addGap(low, high)
+			continue
+		}
+
+		s.owning[idx] = true
+		s.addInterval(low, high, s.ranges[idx])
+	}
+}
+
+func (s *Snapshot) addGap(low, high uint64) {
+	if n := len(s.gaps); n > 0 && s.gaps[n-1].High+1 == low {
+		s.gaps[n-1].High = high
+		return
+	}
+
+	s.gaps = append(s.gaps, Gap{Low: low, High: high})
+}
+
+func (s *Snapshot) addInterval(low, high uint64, owner Range) {
+	if n := len(s.intervals); n > 0 && s.intervals[n-1].Owner == owner && s.intervals[n-1].High+1 == low {
+		s.intervals[n-1].High = high
+		return
+	}
+
+	s.intervals = append(s.intervals, Interval{Low: low, High: high, Owner: owner})
+}
+
+// owner returns the index of the range that owns the hash. Ranges are
+// sorted by term, so the last one that covers it wins.
+func (s *Snapshot) owner(hash uint64) (idx int, ok bool) {
+	for i, r := range s.ranges {
+		if hash >= r.Name.Low && hash <= r.Name.High {
+			idx, ok = i, true
+		}
+	}
+
+	return idx, ok
+}
+
+// Ranges returns every decoded range, oldest term first.
+func (s *Snapshot) Ranges() []Range {
+	return s.ranges
+}
+
+// Invalid returns the files that could not be decoded.
+func (s *Snapshot) Invalid() []string {
+	return s.invalid
+}
+
+// LastTerm returns the highest term of any range.
+func (s *Snapshot) LastTerm() uint64 {
+	if len(s.ranges) == 0 {
+		return 0
+	}
+
+	return s.ranges[len(s.ranges)-1].Name.Term
+}
+
+// Owner returns the range that owns the hash.
+func (s *Snapshot) Owner(hash uint64) (owner Range, ok bool) {
+	idx, ok := s.owner(hash)
+	if !ok {
+		return Range{}, false
+	}
+
+	return s.ranges[idx], true
+}
+
+// Covering returns every range that covers the hash, including superseded
+// ones, oldest term first.
+func (s *Snapshot) Covering(hash uint64) (ranges []Range) {
+	return s.Intersecting(hash, hash)
+}
+
+// Intersecting returns every range that covers any hash from low to high,
+// including superseded ones, oldest term first.
+func (s *Snapshot) Intersecting(low, high uint64) (ranges []Range) {
+	for _, r := range s.ranges {
+		if low <= r.Name.High && high >= r.Name.Low {
+			ranges = append(ranges, r)
+		}
+	}
+
+	return ranges
+}
+
+// Owners returns the ranges that own any hash from low to high, in hash
+// order.
+func (s *Snapshot) Owners(low, high uint64) (ranges []Range) {
+	for _, i := range s.intervals {
+		if low <= i.High && high >= i.Low {
+			ranges = append(ranges, i.Owner)
+		}
+	}
+
+	return ranges
+}
+
+// Intervals returns the owner of every span of hashes that has one, in
+// hash order.
+func (s *Snapshot) Intervals() []Interval {
+	return s.intervals
+}
+
+// Gaps returns every span of hashes without a range, in hash order.
+func (s *Snapshot) Gaps() []Gap {
+	return s.gaps
+}
+
+// Superseded returns the ranges that no longer own any hash, oldest term
+// first.
+func (s *Snapshot) Superseded() (ranges []Range) {
+	for i, r := range s.ranges {
+		if !s.owning[i] {
+			ranges = append(ranges, r)
+		}
+	}
+
+	return ranges
+}
+
+// Overlaps returns the spans covered by two ranges that both still own
+// hashes.
+func (s *Snapshot) Overlaps() (overlaps []Overlap) {
+	for i, older := range s.ranges {
+		if !s.owning[i] {
+			continue
+		}
+
+		for j, newer := range s.ranges[i+1:] {
+			if !s.owning[i+1+j] {
+				continue
+			}
+
+			low, high, ok := intersect(older.Name, newer.Name)
+			if !ok {
+				continue
+			}
+
+			overlaps = append(overlaps, Overlap{
+				Low:   low,
+				High:  high,
+				Older: older,
+				Newer: newer,
+			})
+		}
+	}
+
+	return overlaps
+}
+
+// Validate reports every broken invariant: each range's Low must not be
+// above its High, every hash must have a range and ranges that overlap
+// must have different terms.
+func (s *Snapshot) Validate() error {
+	var errs []error
+	for _, r := range s.ranges {
+		if r.Name.Low > r.Name.High {
+			errs = append(errs, fmt.Errorf("%s: low is above high", r.File))
+		}
+	}
+
+	for _, g := range s.gaps {
+		errs = append(errs, fmt.Errorf("hashes %d-%d have no range", g.Low, g.High))
+	}
+
+	for i, x := range s.ranges {
+		for _, y := range s.ranges[i+1:] {
+			if x.Name.Term != y.Name.Term {
+				continue
+			}
+
+			if _, _, ok := intersect(x.Name, y.Name); ok {
+				errs = append(errs, fmt.Errorf("%s and %s overlap with the same term %d", x.File, y.File, x.Name.Term))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func intersect(x, y RangeName) (low, high uint64, ok bool) {
+	low = max(x.Low, y.Low)
+	high = min(x.High, y.High)
+
+	return low, high, low <= high
+}
@@ -0,0 +1,162 @@
+package topology_test
+
+import (
+	"testing"
+
+	"github.com/poy/onpar"
+	. "github.com/poy/onpar/expect"
+	. "github.com/poy/onpar/matchers"
+	"github.com/poy/petasos/router"
+	"github.com/poy/petasos/topology"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+	o := onpar.New()
+	defer o.Run(t)
+
+	o.Group("when a range has been split", func() {
+		o.Spec("it reports the owner of each hash", func(t *testing.T) {
+			s := topology.New([]string{
+				file(0, 18446744073709551615, 0),
+				file(0, 9223372036854775807, 1),
+				file(9223372036854775808, 18446744073709551615, 2),
+			}, router.JSONCodec{})
+
+			Expect(t, s.Intervals()).To(Equal([]topology.Interval{
+				{Low: 0, High: 9223372036854775807, Owner: rng(0, 9223372036854775807, 1)},
+				{Low: 9223372036854775808, High: 18446744073709551615, Owner: rng(9223372036854775808, 18446744073709551615, 2)},
+			}))
+
+			owner, ok := s.Owner(5)
+			Expect(t, ok).To(BeTrue())
+			Expect(t, owner).To(Equal(rng(0, 9223372036854775807, 1)))
+
+			Expect(t, s.Superseded()).To(Equal([]topology.Range{rng(0, 18446744073709551615, 0)}))
+			Expect(t, s.Overlaps()).To(HaveLen(0))
+			Expect(t, s.Gaps()).To(HaveLen(0))
+			Expect(t, s.LastTerm()).To(Equal(uint64(2)))
+			Expect(t, s.Validate() == nil).To(BeTrue())
+		})
+
+		o.Spec("it reports every range covering a hash", func(t *testing.T) {
+			s := topology.New([]string{
+				file(9223372036854775808, 18446744073709551615, 2),
+				file(0, 18446744073709551615, 0),
+				file(0, 9223372036854775807, 1),
+			}, router.JSONCodec{})
+
+			Expect(t, s.Covering(5)).To(Equal([]topology.Range{
+				rng(0, 18446744073709551615, 0),
+				rng(0, 9223372036854775807, 1),
+			}))
+		})
+
+		o.Spec("it reports every range intersecting a span of hashes and its owners", func(t *testing.T) {
+			s := topology.New([]string{
+				file(9223372036854775808, 18446744073709551615, 2),
+				file(0, 18446744073709551615, 0),
+				file(0, 9223372036854775807, 1),
+			}, router.JSONCodec{})
+
+			Expect(t, s.Intersecting(5, 9223372036854775808)).To(Equal([]topology.Range{
+				rng(0, 18446744073709551615, 0),
+				rng(0, 9223372036854775807, 1),
+				rng(9223372036854775808, 18446744073709551615, 2),
+			}))
+			Expect(t, s.Owners(5, 9223372036854775808)).To(Equal([]topology.Range{
+				rng(0, 9223372036854775807, 1),
+				rng(9223372036854775808, 18446744073709551615, 2),
+			}))
+			Expect(t, s.Owners(5, 10)).To(Equal([]topology.Range{
+				rng(0, 9223372036854775807, 1),
+			}))
+		})
+	})
+
+	o.Spec("it reports a range that is only partially superseded", func(t *testing.T) {
+		s := topology.New([]string{
+			file(0, 18446744073709551615, 0),
+			file(0, 9223372036854775807, 1),
+		}, router.JSONCodec{})
+
+		Expect(t, s.Intervals()).To(HaveLen(2))
+		Expect(t, s.Superseded()).To(HaveLen(0))
+		Expect(t, s.Overlaps()).To(Equal([]topology.Overlap{{
+			Low:   0,
+			High:  9223372036854775807,
+			Older: rng(0, 18446744073709551615, 0),
+			Newer: rng(0, 9223372036854775807, 1),
+		}}))
+	})
+
+	o.Spec("it reports gaps", func(t *testing.T) {
+		s := topology.New([]string{
+			file(10, 100, 0),
+			file(200, 18446744073709551615, 1),
+		}, router.JSONCodec{})
+
+		Expect(t, s.Gaps()).To(Equal([]topology.Gap{
+			{Low: 0, High: 9},
+			{Low: 101, High: 199},
+		}))
+
+		_, ok := s.Owner(150)
+		Expect(t, ok).To(BeFalse())
+		Expect(t, s.Validate() == nil).To(BeFalse())
+	})
+
+	o.Spec("it reports the whole hash space as a gap when there are no ranges", func(t *testing.T) {
+		s := topology.New(nil, router.JSONCodec{})
+		Expect(t, s.Gaps()).To(Equal([]topology.Gap{{Low: 0, High: 18446744073709551615}}))
+		Expect(t, s.Validate() == nil).To(BeFalse())
+	})
+
+	o.Spec("it reports files it can not decode", func(t *testing.T) {
+		s := topology.New([]string{"some-file", file(0, 18446744073709551615, 0)}, router.JSONCodec{})
+		Expect(t, s.Invalid()).To(Equal([]string{"some-file"}))
+		Expect(t, s.Ranges()).To(HaveLen(1))
+	})
+
+	o.Spec("it ignores files in other topics", func(t *testing.T) {
+		codec, err := router.NewTopicCodec("some-topic", router.JSONCodec{})
+		Expect(t, err == nil).To(BeTrue())
+		s := topology.New([]string{
+			"other-topic/" + file(0, 18446744073709551615, 0),
+			codec.Encode(router.RangeName{High: 18446744073709551615, Term: 1}),
+		}, codec)
+
+		Expect(t, s.Invalid()).To(HaveLen(0))
+		Expect(t, s.Ranges()).To(HaveLen(1))
+	})
+
+	o.Spec("it rejects overlapping ranges with the same term", func(t *testing.T) {
+		s := topology.New([]string{
+			file(0, 18446744073709551615, 1),
+			file(0, 100, 1),
+		}, router.JSONCodec{})
+
+		Expect(t, s.Validate() == nil).To(BeFalse())
+	})
+
+	o.Spec("it rejects ranges with low above high", func(t *testing.T) {
+		s := topology.New([]string{
+			file(0, 18446744073709551615, 0),
+			file(100, 10, 1),
+		}, router.JSONCodec{})
+
+		Expect(t, s.Validate() == nil).To(BeFalse())
+		Expect(t, s.Superseded()).To(Equal([]topology.Range{rng(100, 10, 1)}))
+	})
+}
+
+func file(low, high, term uint64) string {
+	return router.JSONCodec{}.Encode(router.RangeName{Low: low, High: high, Term: term})
+}
+
+func rng(low, high, term uint64) topology.Range {
+	return topology.Range{
+		File: file(low, high, term),
+		Name: router.RangeName{Low: low, High: high, Term: term},
+	}
+}